@@ -5,11 +5,14 @@ import (
 	"FacturacionSunat/internal/platform/signer"
 	"FacturacionSunat/internal/platform/storage"
 	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/internal/platform/sunat/oauth"
 	"FacturacionSunat/internal/service"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -25,16 +28,31 @@ func main() {
 	certPass := os.Getenv("CERT_PASS")
 	sunatUsername := os.Getenv("SUNAT_USER")
 	sunatPassword := os.Getenv("SUNAT_PASS")
+	sunatClientID := os.Getenv("SUNAT_CLIENT_ID") // API credentials for the REST services (GRE)
+	sunatClientSecret := os.Getenv("SUNAT_CLIENT_SECRET")
+	exchangeRatesFile := os.Getenv("EXCHANGE_RATES_FILE") // Optional CSV of daily rates loaded at startup
 
 	if certPass == "" || sunatUsername == "" || sunatPassword == "" {
 		log.Fatal("Las variables de entorno CERT_PASS, SUNAT_USER y SUNAT_PASS son requeridas. Puedes definirlas en un archivo .env")
 	}
 
 	// URL del servicio de SUNAT (beta por defecto)
-	sunatBaseURL := "https://e-beta.sunat.gob.pe/ol-ti-itcpfegem-beta/billService?wsdl" // WSDL for sendBill and getStatus
+	sunatBaseURL := "https://e-beta.sunat.gob.pe/ol-ti-itcpfegem-beta/billService?wsdl"             // WSDL for sendBill and getStatus
+	otherCPEURL := "https://e-beta.sunat.gob.pe/ol-ti-itemision-otroscpe-gem-beta/billService?wsdl" // WSDL for retentions and perceptions
+	greBaseURL := "https://api-cpe.sunat.gob.pe/v1/contribuyente/gem"                               // REST API for remission guides
 
 	// 1. Initialize dependencies (the "platform" layer).
 	invoiceRepo := storage.NewInvoiceMemoryRepo() // Usando el repositorio en memoria
+	noteRepo := storage.NewNoteMemoryRepo()
+	summaryRepo := storage.NewSummaryMemoryRepo()
+	draftRepo := storage.NewDebitNoteDraftMemoryRepo()
+	contingencyRepo := storage.NewContingencyMemoryRepo()
+	despatchRepo := storage.NewDespatchAdviceMemoryRepo()
+	retentionRepo := storage.NewRetentionMemoryRepo()
+	perceptionRepo := storage.NewPerceptionReceiptMemoryRepo()
+	reversalRepo := storage.NewReversalSummaryMemoryRepo()
+	settlementRepo := storage.NewPurchaseSettlementMemoryRepo()
+	exchangeRateRepo := storage.NewExchangeRateMemoryRepo()
 	signer, err := signer.NewXMLSigner(certPath, certPass)
 	if err != nil {
 		log.Fatalf("Error al inicializar el firmador digital: %v", err)
@@ -43,20 +61,74 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error al inicializar el cliente de SUNAT: %v", err)
 	}
+	otherCPEClient, err := sunat.NewClient(otherCPEURL, sunatUsername, sunatPassword)
+	if err != nil {
+		log.Fatalf("Error al inicializar el cliente de retenciones y percepciones de SUNAT: %v", err)
+	}
+	tokenProvider := oauth.NewProvider(oauth.DefaultBaseURL, &http.Client{Timeout: 30 * time.Second})
+	if sunatClientID != "" && len(sunatUsername) > 11 {
+		// The SOL user is the RUC of the issuer followed by the user name.
+		tokenProvider.SetCredentials(sunatUsername[:11], oauth.Credentials{
+			ClientID:     sunatClientID,
+			ClientSecret: sunatClientSecret,
+			Username:     sunatUsername,
+			Password:     sunatPassword,
+		})
+	}
+	greClient := sunat.NewGREClient(greBaseURL, &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &oauth.Transport{Provider: tokenProvider},
+	})
 
 	// 2. Initialize the core logic (the "service" layer).
-	invoiceService := service.NewInvoiceService(invoiceRepo, signer, sunatClient)
+	invoiceService := service.NewInvoiceService(invoiceRepo, noteRepo, summaryRepo, draftRepo, contingencyRepo, exchangeRateRepo, signer, sunatClient)
+	go invoiceService.StartInterestJob(context.Background(), 24*time.Hour) // Interest drafts for overdue installments
+	despatchService := service.NewDespatchService(despatchRepo, signer, greClient)
+	retentionService := service.NewRetentionService(retentionRepo, exchangeRateRepo, signer, otherCPEClient)
+	perceptionService := service.NewPerceptionService(perceptionRepo, exchangeRateRepo, signer, otherCPEClient)
+	reversalService := service.NewReversalService(reversalRepo, retentionRepo, perceptionRepo, signer, otherCPEClient)
+	settlementService := service.NewPurchaseSettlementService(settlementRepo, exchangeRateRepo, signer, sunatClient)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo)
+	if exchangeRatesFile != "" {
+		if err := importExchangeRates(exchangeRateService, exchangeRatesFile); err != nil {
+			log.Fatalf("Error al importar los tipos de cambio: %v", err)
+		}
+	}
 
 	// 3. Initialize the entrypoint (the "handler" layer).
 	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	despatchHandler := handler.NewDespatchHandler(despatchService)
+	retentionHandler := handler.NewRetentionHandler(retentionService, reversalService)
+	perceptionHandler := handler.NewPerceptionHandler(perceptionService, reversalService)
+	reversalHandler := handler.NewReversalHandler(reversalService)
+	settlementHandler := handler.NewPurchaseSettlementHandler(settlementService)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateService)
 
 	// 4. Register API routes.
 	apiV1 := http.NewServeMux()
 	apiV1.HandleFunc("/api/v1/invoices", invoiceHandler.CreateInvoice)
+	apiV1.HandleFunc("/api/v1/invoices/", invoiceHandler.GetInvoiceBalance) // Handles /api/v1/invoices/{id}/balance
 	apiV1.HandleFunc("/api/v1/credit-notes", invoiceHandler.CreateCreditNote)
 	apiV1.HandleFunc("/api/v1/debit-notes", invoiceHandler.CreateDebitNote)
-	apiV1.HandleFunc("/api/v1/documents/", invoiceHandler.GetDocumentStatus)       // Handles /api/v1/documents/{id}/status
-	apiV1.HandleFunc("/api/v1/documents/cdr", invoiceHandler.GetDocumentStatusCdr) // Handles /api/v1/documents/cdr?ruc=...&docType=...&series=...&number=...
+	apiV1.HandleFunc("/api/v1/summaries", invoiceHandler.SendDailySummary)
+	apiV1.HandleFunc("/api/v1/interest-policies", invoiceHandler.SetInterestPolicy)
+	apiV1.HandleFunc("/api/v1/contingency", invoiceHandler.HandleContingency)                      // POST to start, DELETE ?ruc=... to end and report
+	apiV1.HandleFunc("/api/v1/contingency-summaries/", invoiceHandler.GetContingencySummaryStatus) // Handles /api/v1/contingency-summaries/{id}/status
+	apiV1.HandleFunc("/api/v1/debit-note-drafts", invoiceHandler.ListDebitNoteDrafts)              // Handles /api/v1/debit-note-drafts?ruc=...&status=...
+	apiV1.HandleFunc("/api/v1/debit-note-drafts/", invoiceHandler.HandleDebitNoteDraft)            // Handles /api/v1/debit-note-drafts/{id}/issue and /{id}/discard
+	apiV1.HandleFunc("/api/v1/documents/", invoiceHandler.GetDocumentStatus)                       // Handles /api/v1/documents/{id}/status
+	apiV1.HandleFunc("/api/v1/documents/cdr", invoiceHandler.GetDocumentStatusCdr)                 // Handles /api/v1/documents/cdr?ruc=...&docType=...&series=...&number=...
+	apiV1.HandleFunc("/api/v1/reports/perceptions", invoiceHandler.GetPerceptionReport)            // Handles /api/v1/reports/perceptions?ruc=...&from=...&to=...
+	apiV1.HandleFunc("/api/v1/despatch-advices", despatchHandler.CreateDespatchAdvice)
+	apiV1.HandleFunc("/api/v1/despatch-advices/", despatchHandler.HandleDespatchAdvice) // Handles /api/v1/despatch-advices/{id}/status and /{id}/cdr
+	apiV1.HandleFunc("/api/v1/retentions", retentionHandler.CreateRetention)
+	apiV1.HandleFunc("/api/v1/retentions/", retentionHandler.HandleRetention)    // Handles /api/v1/retentions/{id} and /{id}/revert
+	apiV1.HandleFunc("/api/v1/perceptions", perceptionHandler.HandlePerceptions) // POST to create, GET /api/v1/perceptions?ruc=...&from=...&to=... to list
+	apiV1.HandleFunc("/api/v1/perceptions/", perceptionHandler.HandlePerception) // Handles /api/v1/perceptions/{id}/status and /{id}/revert
+	apiV1.HandleFunc("/api/v1/reversals/", reversalHandler.GetReversalStatus)    // Handles /api/v1/reversals/{id}/status
+	apiV1.HandleFunc("/api/v1/purchase-settlements", settlementHandler.CreatePurchaseSettlement)
+	apiV1.HandleFunc("/api/v1/purchase-settlements/", settlementHandler.GetPurchaseSettlement) // Handles /api/v1/purchase-settlements/{id}
+	apiV1.HandleFunc("/api/v1/exchange-rates", exchangeRateHandler.HandleExchangeRates)        // POST a CSV file to import, GET ?currency=USD&date=... to look up
 	apiV1.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintln(w, "OK")
@@ -74,3 +146,19 @@ func main() {
 		log.Fatalf("Error al iniciar el servidor: %s\n", err)
 	}
 }
+
+// importExchangeRates loads the daily exchange rates of a CSV file.
+func importExchangeRates(s *service.ExchangeRateService, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	imported, err := s.Import(file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Tipos de cambio importados desde %s: %d\n", path, imported)
+	return nil
+}
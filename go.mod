@@ -5,13 +5,13 @@ go 1.24.4
 require (
 	github.com/beevik/etree v1.1.0
 	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
 	github.com/russellhaering/goxmldsig v1.1.0
 	github.com/tiaguinho/gosoap v1.4.4
 	golang.org/x/crypto v0.40.0
 )
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/jonboulle/clockwork v0.2.0 // indirect
 	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/text v0.27.0 // indirect
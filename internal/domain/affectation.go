@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"time"
+)
+
+// Tax scheme codes (SUNAT catalog 05) of operations that do not pay IGV.
+const (
+	TaxSchemeExport     = "9995" // Exportación
+	TaxSchemeFree       = "9996" // Gratuito
+	TaxSchemeExonerated = "9997" // Exonerado
+	TaxSchemeUnaffected = "9998" // Inafecto
+)
+
+// AffectationTaxed is the catalog 07 code of a taxed sale, the default for lines.
+const AffectationTaxed = "10"
+
+// AffectationUnaffected is the catalog 07 code of an onerous operation not subject to IGV.
+const AffectationUnaffected = "30"
+
+// AffectationExport is the catalog 07 code of exported goods and services.
+const AffectationExport = "40"
+
+// affectation describes a catalog 07 IGV affectation code.
+type affectation struct {
+	scheme string // Catalog 05 scheme of the line value
+	free   bool   // Free transfer: the value is referential and not charged
+}
+
+// affectations holds the catalog 07 codes.
+var affectations = map[string]affectation{
+	"10":              {scheme: TaxSchemeIGV},                    // Gravado - Operación Onerosa
+	"11":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Retiro por premio
+	"12":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Retiro por donación
+	"13":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Retiro
+	"14":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Retiro por publicidad
+	"15":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Bonificaciones
+	"16":              {scheme: TaxSchemeIGV, free: true},        // Gravado - Retiro por entrega a trabajadores
+	"17":              {scheme: TaxSchemeIVAP},                   // Gravado - IVAP
+	"20":              {scheme: TaxSchemeExonerated},             // Exonerado - Operación Onerosa
+	"21":              {scheme: TaxSchemeExonerated, free: true}, // Exonerado - Transferencia gratuita
+	"30":              {scheme: TaxSchemeUnaffected},             // Inafecto - Operación Onerosa
+	"31":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro por bonificación
+	"32":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro
+	"33":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro por muestras médicas
+	"34":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro por convenio colectivo
+	"35":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro por premio
+	"36":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Retiro por publicidad
+	"37":              {scheme: TaxSchemeUnaffected, free: true}, // Inafecto - Transferencia gratuita
+	AffectationExport: {scheme: TaxSchemeExport},                 // Exportación de bienes o servicios
+}
+
+// TaxPercents holds the percentage of each tax scheme that charges tax (IGV, IVAP).
+type TaxPercents map[string]decimal.Decimal
+
+// Percents resolves the percentages of the taxed schemes for the issuer regime and date.
+// IGV is required; IVAP is only included when a rate is in force.
+func (r *TaxRateRegistry) Percents(regime string, date time.Time) (TaxPercents, error) {
+	igv, err := r.Lookup(TaxSchemeIGV, regime, date)
+	if err != nil {
+		return nil, err
+	}
+	percents := TaxPercents{TaxSchemeIGV: igv}
+	if ivap, err := r.Lookup(TaxSchemeIVAP, regime, date); err == nil {
+		percents[TaxSchemeIVAP] = ivap
+	}
+	return percents, nil
+}
+
+// Affectation returns the catalog 07 code of the line, taxed by default.
+func (l InvoiceLine) Affectation() string {
+	if l.AffectationCode == "" {
+		return AffectationTaxed
+	}
+	return l.AffectationCode
+}
+
+// TaxScheme returns the catalog 05 scheme of the line value.
+func (l InvoiceLine) TaxScheme() string {
+	return affectations[l.Affectation()].scheme
+}
+
+// IsFree reports whether the line is a free transfer whose value is only referential.
+func (l InvoiceLine) IsFree() bool {
+	return affectations[l.Affectation()].free
+}
+
+// taxPercent returns the percentage of tax charged on the line value.
+func (l InvoiceLine) taxPercent(percents TaxPercents) (decimal.Decimal, error) {
+	a, ok := affectations[l.Affectation()]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("tipo de afectación del IGV %q no soportado", l.AffectationCode)
+	}
+	switch a.scheme {
+	case TaxSchemeIGV, TaxSchemeIVAP:
+		percent, ok := percents[a.scheme]
+		if !ok {
+			return decimal.Zero, fmt.Errorf("no existe tasa para el tributo %s", a.scheme)
+		}
+		return percent, nil
+	}
+	return decimal.Zero, nil
+}
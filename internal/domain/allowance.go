@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+)
+
+// AllowanceCharge is a discount or charge (catalog 53) applied to a line or to the whole document.
+// Whether it is a charge follows from its reason code.
+type AllowanceCharge struct {
+	ReasonCode string          `json:"codigo_motivo"`       // Catalog 53
+	Factor     decimal.Decimal `json:"factor,omitzero"`     // e.g. 0.10 for 10%
+	Amount     decimal.Decimal `json:"monto"`               // Calculated from Factor x BaseAmount when empty
+	BaseAmount decimal.Decimal `json:"monto_base,omitzero"` // Defaults to the line or document sales value
+}
+
+// allowanceChargeReason describes how a catalog 53 code behaves.
+type allowanceChargeReason struct {
+	charge      bool // true for charges, false for discounts
+	affectsBase bool // true if it modifies the IGV/IVAP taxable base
+	line        bool // true if it applies to a line, false if it applies to the document
+}
+
+// allowanceChargeReasons holds the catalog 53 codes supported by the system.
+var allowanceChargeReasons = map[string]allowanceChargeReason{
+	"00": {charge: false, affectsBase: true, line: true},   // Descuentos que afectan la base imponible
+	"01": {charge: false, affectsBase: false, line: true},  // Descuentos que no afectan la base imponible
+	"02": {charge: false, affectsBase: true, line: false},  // Descuentos globales que afectan la base imponible
+	"03": {charge: false, affectsBase: false, line: false}, // Descuentos globales que no afectan la base imponible
+	"04": {charge: false, affectsBase: true, line: false},  // Descuentos globales por anticipos gravados
+	"45": {charge: true, affectsBase: false, line: false},  // FISE
+	"46": {charge: true, affectsBase: false, line: false},  // Recargo al consumo y/o propinas
+	"47": {charge: true, affectsBase: true, line: true},    // Cargos que afectan la base imponible
+	"48": {charge: true, affectsBase: false, line: true},   // Cargos que no afectan la base imponible
+	"49": {charge: true, affectsBase: true, line: false},   // Cargos globales que afectan la base imponible
+	"50": {charge: true, affectsBase: false, line: false},  // Cargos globales que no afectan la base imponible
+}
+
+// IsCharge reports whether the reason code corresponds to a charge rather than a discount.
+func (ac AllowanceCharge) IsCharge() bool {
+	return allowanceChargeReasons[ac.ReasonCode].charge
+}
+
+// resolve validates the reason code for the given level, fills the base amount
+// with defaultBase when empty and computes the amount from the factor.
+func (ac *AllowanceCharge) resolve(defaultBase decimal.Decimal, line bool) (allowanceChargeReason, error) {
+	reason, ok := allowanceChargeReasons[ac.ReasonCode]
+	if !ok {
+		return reason, fmt.Errorf("código de cargo/descuento %q no soportado", ac.ReasonCode)
+	}
+	if reason.line != line {
+		level := "global"
+		if reason.line {
+			level = "de ítem"
+		}
+		return reason, fmt.Errorf("el código de cargo/descuento %s solo aplica a nivel %s", ac.ReasonCode, level)
+	}
+	if ac.BaseAmount.IsZero() {
+		ac.BaseAmount = defaultBase
+	}
+	if ac.Amount.IsZero() && !ac.Factor.IsZero() {
+		ac.Amount = Round2(ac.BaseAmount.Mul(ac.Factor))
+	}
+	if ac.Amount.IsNegative() {
+		return reason, fmt.Errorf("el monto del cargo/descuento %s no puede ser negativo", ac.ReasonCode)
+	}
+	return reason, nil
+}
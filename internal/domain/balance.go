@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+)
+
+// DocumentBalance is the net amount of an invoice after the credit and debit notes that
+// reference it, overall and per line.
+type DocumentBalance struct {
+	InvoiceID    string          `json:"id"`
+	ReferenceID  string          `json:"nro_comprobante"`
+	Currency     string          `json:"moneda"`
+	Total        decimal.Decimal `json:"total"`
+	Credited     decimal.Decimal `json:"total_notas_credito"`
+	Debited      decimal.Decimal `json:"total_notas_debito"`
+	Balance      decimal.Decimal `json:"saldo"`
+	ExchangeRate decimal.Decimal `json:"tipo_cambio,omitzero"` // Of invoices in foreign currency
+	BalancePEN   decimal.Decimal `json:"saldo_pen,omitzero"`   // Balance in soles at the exchange rate of the invoice
+	Lines        []LineBalance   `json:"items"`
+}
+
+// LineBalance is the net value, IGV excluded, of an invoice line after the notes that adjust it.
+type LineBalance struct {
+	ID          string          `json:"id,omitempty"`
+	Code        string          `json:"codigo,omitempty"`
+	Description string          `json:"descripcion"`
+	Value       decimal.Decimal `json:"valor_total"`
+	Credited    decimal.Decimal `json:"valor_notas_credito"`
+	Debited     decimal.Decimal `json:"valor_notas_debito"`
+	Balance     decimal.Decimal `json:"saldo"`
+}
+
+// CheckAffectable rejects rejected or voided invoices as the target of credit and debit notes.
+func (inv *Invoice) CheckAffectable() error {
+	switch inv.Status {
+	case "RECHAZADO", "ANULADO":
+		return fmt.Errorf("el comprobante %s-%d está %s y no puede ser afectado", inv.Series, inv.Number, inv.Status)
+	}
+	return nil
+}
+
+// NewDocumentBalance adds up the notes that reference the invoice. Rejected notes are ignored;
+// notes still in process count so that concurrent notes cannot exceed the balance.
+func NewDocumentBalance(inv *Invoice, credits []*CreditNote, debits []*DebitNote) *DocumentBalance {
+	b := &DocumentBalance{
+		InvoiceID:   inv.ID,
+		ReferenceID: fmt.Sprintf("%s-%d", inv.Series, inv.Number),
+		Currency:    inv.Currency,
+		Total:       inv.Totals.Total,
+	}
+	for _, line := range inv.Lines {
+		b.Lines = append(b.Lines, LineBalance{
+			ID:          line.ID,
+			Code:        line.Code,
+			Description: line.Description,
+			Value:       line.TotalValue,
+		})
+	}
+
+	for _, cn := range credits {
+		if cn.Status == "RECHAZADO" {
+			continue
+		}
+		b.Credited = b.Credited.Add(cn.Totals.Total)
+		for _, line := range cn.Lines {
+			if lb := b.findLine(line); lb != nil {
+				lb.Credited = lb.Credited.Add(line.TotalValue)
+			}
+		}
+	}
+	for _, dn := range debits {
+		if dn.Status == "RECHAZADO" {
+			continue
+		}
+		b.Debited = b.Debited.Add(dn.Totals.Total)
+		for _, line := range dn.Lines {
+			if lb := b.findLine(line); lb != nil {
+				lb.Debited = lb.Debited.Add(line.TotalValue)
+			}
+		}
+	}
+
+	b.Credited = Round2(b.Credited)
+	b.Debited = Round2(b.Debited)
+	b.Balance = Round2(b.Total.Add(b.Debited).Sub(b.Credited))
+	if inv.PEN != nil {
+		b.ExchangeRate = inv.PEN.ExchangeRate
+		b.BalancePEN = inv.PEN.Convert(b.Balance)
+	}
+	for i := range b.Lines {
+		lb := &b.Lines[i]
+		lb.Credited = Round2(lb.Credited)
+		lb.Debited = Round2(lb.Debited)
+		lb.Balance = Round2(lb.Value.Add(lb.Debited).Sub(lb.Credited))
+	}
+	return b
+}
+
+// CheckCredit rejects a credit note that would leave the invoice, or any of its lines,
+// with a negative balance.
+func (b *DocumentBalance) CheckCredit(cn *CreditNote) error {
+	if cn.Totals.Total.GreaterThan(b.Balance) {
+		return fmt.Errorf("el total de la nota de crédito (%s) excede el saldo del comprobante %s (%s)", cn.Totals.Total, b.ReferenceID, b.Balance)
+	}
+
+	credited := make(map[*LineBalance]decimal.Decimal)
+	for _, line := range cn.Lines {
+		if lb := b.findLine(line); lb != nil {
+			credited[lb] = credited[lb].Add(line.TotalValue)
+		}
+	}
+	for lb, value := range credited {
+		if Round2(value).GreaterThan(lb.Balance) {
+			return fmt.Errorf("el valor acreditado del ítem %q (%s) excede su saldo (%s)", lb.Description, Round2(value), lb.Balance)
+		}
+	}
+	return nil
+}
+
+// findLine matches a note line with an invoice line by ID, then by product code and
+// finally by description.
+func (b *DocumentBalance) findLine(line InvoiceLine) *LineBalance {
+	for i := range b.Lines {
+		lb := &b.Lines[i]
+		switch {
+		case line.ID != "" && lb.ID != "":
+			if line.ID == lb.ID {
+				return lb
+			}
+		case line.Code != "" && lb.Code != "":
+			if line.Code == lb.Code {
+				return lb
+			}
+		case line.Description == lb.Description:
+			return lb
+		}
+	}
+	return nil
+}
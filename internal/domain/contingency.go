@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// contingencyReasons holds the reasons of a contingency reported in the RF summary.
+var contingencyReasons = map[string]string{
+	"1": "Conexión a internet",
+	"2": "Fallas fluido eléctrico",
+	"3": "Desastres naturales",
+	"4": "Robo",
+	"5": "Fallas en el sistema de facturación electrónica",
+	"6": "Ventas por emisores itinerantes",
+	"7": "Otros",
+}
+
+// contingencySeriesPattern matches the numeric series of contingency documents.
+var contingencySeriesPattern = regexp.MustCompile(`^\d{4}$`)
+
+// Contingency is the period in which an issuer cannot issue electronic documents, because
+// its systems or SUNAT are unavailable, and issues them with numeric series instead.
+type Contingency struct {
+	RUC    string            `json:"ruc"`
+	Reason string            `json:"motivo"` // 1 to 7, see contingencyReasons
+	Series map[string]string `json:"series"` // Contingency series by catalog 01 type, e.g. {"01": "0001", "03": "0002"}
+	Since  time.Time         `json:"desde"`
+}
+
+// SeriesFor returns the contingency series assigned to the document type.
+func (c Contingency) SeriesFor(docType string) (string, error) {
+	series, ok := c.Series[docType]
+	if !ok {
+		return "", fmt.Errorf("no hay serie de contingencia asignada al tipo de comprobante %s", docType)
+	}
+	return series, nil
+}
+
+// validate checks the reason and the numeric series of the contingency.
+func (c Contingency) validate() error {
+	if c.RUC == "" {
+		return fmt.Errorf("el RUC del emisor es requerido")
+	}
+	if _, ok := contingencyReasons[c.Reason]; !ok {
+		return fmt.Errorf("motivo de contingencia %q no soportado", c.Reason)
+	}
+	if len(c.Series) == 0 {
+		return fmt.Errorf("se requiere al menos una serie de contingencia")
+	}
+	for docType, series := range c.Series {
+		if !contingencySeriesPattern.MatchString(series) || series == "0000" {
+			return fmt.Errorf("la serie de contingencia %q del tipo %s debe ser numérica de 4 dígitos", series, docType)
+		}
+	}
+	return nil
+}
+
+// ContingencyRegistry holds the issuers currently in contingency.
+type ContingencyRegistry struct {
+	mu            sync.RWMutex
+	contingencies map[string]Contingency
+}
+
+// NewContingencyRegistry creates an empty registry.
+func NewContingencyRegistry() *ContingencyRegistry {
+	return &ContingencyRegistry{contingencies: make(map[string]Contingency)}
+}
+
+// Start validates and registers the contingency of an issuer, replacing the previous one.
+func (r *ContingencyRegistry) Start(c Contingency) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	if c.Since.IsZero() {
+		c.Since = time.Now()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contingencies[c.RUC] = c
+	return nil
+}
+
+// Lookup returns the contingency of the issuer, if any.
+func (r *ContingencyRegistry) Lookup(ruc string) (Contingency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.contingencies[ruc]
+	return c, ok
+}
+
+// End removes the contingency of the issuer.
+func (r *ContingencyRegistry) End(ruc string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.contingencies, ruc)
+}
+
+// ContingencyLine is a document issued in contingency, waiting to be reported.
+type ContingencyLine struct {
+	InvoiceID  string      `json:"id_comprobante"`
+	Reason     string      `json:"motivo"`
+	DocType    string      `json:"tipo_comprobante"`
+	Series     string      `json:"serie"`
+	Number     int         `json:"numero"`
+	IssueDate  time.Time   `json:"fecha_emision"`
+	Currency   string      `json:"moneda"`
+	Recipient  Recipient   `json:"receptor"`
+	Totals     Totals      `json:"totales"`
+	Perception *Perception `json:"percepcion,omitempty"`
+}
+
+// ContingencyLineFromInvoice builds the line that reports an invoice issued in contingency.
+func ContingencyLineFromInvoice(inv *Invoice, reason string) ContingencyLine {
+	return ContingencyLine{
+		InvoiceID:  inv.ID,
+		Reason:     reason,
+		DocType:    inv.Type,
+		Series:     inv.Series,
+		Number:     inv.Number,
+		IssueDate:  inv.IssueDate,
+		Currency:   inv.Currency,
+		Recipient:  inv.Recipient,
+		Totals:     inv.Totals,
+		Perception: inv.Perception,
+	}
+}
+
+// ContingencySummary is the resumen de comprobantes emitidos en contingencia (RF). Unlike the
+// other summaries it is a pipe separated text file.
+type ContingencySummary struct {
+	ID            string            `json:"id"` // RF-DDMMYYYY-NN
+	RUC           string            `json:"ruc"`
+	ReferenceDate time.Time         `json:"fecha_emision_comprobantes"`
+	IssueDate     time.Time         `json:"fecha_generacion"`
+	Lines         []ContingencyLine `json:"items"`
+	Status        string            `json:"estado"`
+	TicketID      string            `json:"ticket_id,omitempty"`
+	StatusMessage string            `json:"mensaje_estado,omitempty"`
+}
+
+// ContingencySummaryID returns the ID of the n-th contingency summary generated on the day.
+func ContingencySummaryID(day time.Time, n int) string {
+	return fmt.Sprintf("RF-%s-%02d", day.Format("02012006"), n)
+}
+
+// Text renders the summary, one line per document with the fields: reason, issue date, type,
+// series, number, last number of the range, recipient document type, number and name,
+// currency, exported, taxed, exonerated, unaffected and free values, ISC, IGV, other taxes,
+// total, and the perception regime, base, amount and total with perception.
+func (s *ContingencySummary) Text() []byte {
+	var buf bytes.Buffer
+	for _, l := range s.Lines {
+		t := l.Totals
+		fields := []any{
+			l.Reason, l.IssueDate.Format("02/01/2006"), l.DocType, l.Series, l.Number, "",
+			recipientDocCode(l.Recipient.DocType), l.Recipient.DocNum, l.Recipient.Name, l.Currency,
+			amount(t.Exported), amount(t.Taxable), amount(t.Exonerated), amount(t.Unaffected), amount(t.Free),
+			amount(decimal.Zero), amount(t.IGV.Add(t.IVAP)), amount(decimal.Zero), amount(t.Total),
+		}
+		if p := l.Perception; p != nil {
+			fields = append(fields, p.Code, amount(p.BaseAmount), amount(p.Amount), amount(p.TotalWithPerception))
+		} else {
+			fields = append(fields, "", "", "", "")
+		}
+		for i, f := range fields {
+			if i > 0 {
+				buf.WriteByte('|')
+			}
+			fmt.Fprint(&buf, f)
+		}
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
+
+// amount formats an amount with two decimals.
+func amount(v decimal.Decimal) string {
+	return v.StringFixed(2)
+}
+
+// recipientDocCode returns the catalog 06 code of the recipient, "0" when it is empty or unknown.
+func recipientDocCode(docType string) string {
+	if code := IdentityDocCode(docType); code != "" {
+		return code
+	}
+	return "0"
+}
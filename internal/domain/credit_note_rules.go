@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"slices"
+)
+
+// CreditNoteTypeInstallments is the catalog 09 code that corrects the net amount pending
+// payment and/or the due dates of a credit invoice.
+const CreditNoteTypeInstallments = "13"
+
+// creditNoteKind groups the catalog 09 reasons by how they affect the note amounts.
+type creditNoteKind int
+
+const (
+	creditNoteFull         creditNoteKind = iota // Cancels the whole affected invoice
+	creditNoteCorrection                         // Amends descriptive data, amounts are zero
+	creditNotePartial                            // Reduces part of the affected invoice
+	creditNoteInstallments                       // Amends the credit installments, amounts are zero
+)
+
+// creditNoteTypes holds the catalog 09 credit note reasons.
+var creditNoteTypes = map[string]creditNoteKind{
+	"01": creditNoteFull,         // Anulación de la operación
+	"02": creditNoteFull,         // Anulación por error en el RUC
+	"03": creditNoteCorrection,   // Corrección por error en la descripción
+	"04": creditNotePartial,      // Descuento global
+	"05": creditNotePartial,      // Descuento por ítem
+	"06": creditNoteFull,         // Devolución total
+	"07": creditNotePartial,      // Devolución por ítem
+	"08": creditNotePartial,      // Bonificación
+	"09": creditNotePartial,      // Disminución en el valor
+	"10": creditNotePartial,      // Otros conceptos
+	"11": creditNotePartial,      // Ajustes de operaciones de exportación
+	"12": creditNotePartial,      // Ajustes afectos al IVAP
+	"13": creditNoteInstallments, // Ajustes - montos y/o fechas de pago
+}
+
+// IsCreditNoteType reports whether code is a catalog 09 credit note reason.
+func IsCreditNoteType(code string) bool {
+	_, ok := creditNoteTypes[code]
+	return ok
+}
+
+// PrepareLines checks the catalog 09 reason against the affected invoice and completes the
+// lines the reason implies: full annulments copy the invoice lines when none are given, along
+// with its global discounts, charges and prepayments, and corrections carry zero amounts.
+func (cn *CreditNote) PrepareLines(original *Invoice) error {
+	kind, ok := creditNoteTypes[cn.DiscrepancyResponse.TypeCode]
+	if !ok {
+		return fmt.Errorf("tipo de nota de crédito %q no soportado", cn.DiscrepancyResponse.TypeCode)
+	}
+	if err := original.CheckAffectable(); err != nil {
+		return err
+	}
+	if cn.Currency != original.Currency {
+		return fmt.Errorf("la moneda de la nota de crédito debe ser la del comprobante afectado (%s)", original.Currency)
+	}
+	if kind != creditNoteInstallments && cn.PaymentTerms != nil {
+		return fmt.Errorf("solo las notas de crédito tipo %s admiten cuotas", CreditNoteTypeInstallments)
+	}
+	if kind != creditNoteFull && len(cn.Prepayments) > 0 {
+		return fmt.Errorf("solo las anulaciones y devoluciones totales admiten anticipos")
+	}
+
+	cn.DiscrepancyResponse.DocType = original.Type
+
+	switch kind {
+	case creditNoteFull:
+		if len(cn.Lines) == 0 {
+			cn.Lines = cloneLines(original.Lines)
+		}
+		if len(cn.AllowanceCharges) == 0 {
+			cn.AllowanceCharges = slices.Clone(original.AllowanceCharges)
+		}
+		if len(cn.Prepayments) == 0 {
+			cn.Prepayments = slices.Clone(original.Prepayments)
+		}
+	case creditNoteCorrection:
+		if len(cn.Lines) == 0 {
+			return fmt.Errorf("la corrección de descripción requiere los ítems corregidos")
+		}
+		zeroLines(cn.Lines)
+	case creditNoteInstallments:
+		if len(cn.Lines) == 0 {
+			cn.Lines = []InvoiceLine{{Quantity: decimal.NewFromInt(1), Description: cn.DiscrepancyResponse.Description}}
+		}
+		zeroLines(cn.Lines)
+	case creditNotePartial:
+		if len(cn.Lines) == 0 {
+			return fmt.Errorf("la nota de crédito tipo %s requiere al menos un ítem", cn.DiscrepancyResponse.TypeCode)
+		}
+	}
+	return nil
+}
+
+// ValidateAmounts checks the computed totals against the affected invoice: full annulments
+// must match its total, partial notes cannot exceed it, and corrections must be zero. Type 13
+// notes must also carry the corrected credit installments.
+func (cn *CreditNote) ValidateAmounts(original *Invoice) error {
+	total := cn.Totals.Total
+	switch creditNoteTypes[cn.DiscrepancyResponse.TypeCode] {
+	case creditNoteFull:
+		if !total.Equal(original.Totals.Total) {
+			return fmt.Errorf("el total de la anulación (%s) debe ser igual al del comprobante afectado (%s)", total, original.Totals.Total)
+		}
+	case creditNotePartial:
+		if !total.IsPositive() || total.GreaterThan(original.Totals.Total) {
+			return fmt.Errorf("el total de la nota de crédito debe ser mayor a cero y no exceder %s", original.Totals.Total)
+		}
+	case creditNoteCorrection:
+		if !total.IsZero() {
+			return fmt.Errorf("la corrección de descripción no puede tener importes")
+		}
+	case creditNoteInstallments:
+		if !total.IsZero() {
+			return fmt.Errorf("la nota de crédito tipo %s no puede tener importes", CreditNoteTypeInstallments)
+		}
+		return cn.validateInstallments(original)
+	}
+	return nil
+}
+
+// validateInstallments checks the corrected credit terms of a type 13 note.
+func (cn *CreditNote) validateInstallments(original *Invoice) error {
+	if original.Type != "01" || original.PaymentTerms == nil || original.PaymentTerms.Method != PaymentCredit {
+		return fmt.Errorf("la nota de crédito tipo %s solo aplica a facturas al crédito", CreditNoteTypeInstallments)
+	}
+	pt := cn.PaymentTerms
+	if pt == nil || pt.Method != PaymentCredit {
+		return fmt.Errorf("la nota de crédito tipo %s requiere la forma de pago %s con sus cuotas", CreditNoteTypeInstallments, PaymentCredit)
+	}
+	if !pt.PendingAmount.IsPositive() || pt.PendingAmount.GreaterThan(original.Totals.Total) {
+		return fmt.Errorf("el monto neto pendiente debe ser mayor a cero y no exceder %s", original.Totals.Total)
+	}
+	return pt.validateInstallments(cn.IssueDate)
+}
+
+// cloneLines copies lines without sharing their allowances and properties, which resolving
+// the totals of the copy would otherwise modify.
+func cloneLines(lines []InvoiceLine) []InvoiceLine {
+	result := slices.Clone(lines)
+	for i := range result {
+		result[i].AllowanceCharges = slices.Clone(result[i].AllowanceCharges)
+		result[i].Properties = slices.Clone(result[i].Properties)
+	}
+	return result
+}
+
+// zeroLines clears the amounts of lines that only amend descriptive data.
+func zeroLines(lines []InvoiceLine) {
+	for i := range lines {
+		lines[i].UnitPrice = decimal.Zero
+		lines[i].TotalValue = decimal.Zero
+		lines[i].IGV = decimal.Zero
+		lines[i].AllowanceCharges = nil
+	}
+}
@@ -0,0 +1,293 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"time"
+)
+
+// CurrencyPEN is the catalog 02 code of the sol, the currency of the SUNAT amounts.
+const CurrencyPEN = "PEN"
+
+// currencies holds the catalog 02 currencies accepted on documents: the active ISO 4217
+// codes, without the testing (XTS) and no currency (XXX) codes.
+var currencies = map[string]string{
+	"AED": "UAE Dirham",
+	"AFN": "Afghani",
+	"ALL": "Lek",
+	"AMD": "Armenian Dram",
+	"AOA": "Kwanza",
+	"ARS": "Argentine Peso",
+	"AUD": "Australian Dollar",
+	"AWG": "Aruban Florin",
+	"AZN": "Azerbaijan Manat",
+	"BAM": "Convertible Mark",
+	"BBD": "Barbados Dollar",
+	"BDT": "Taka",
+	"BGN": "Bulgarian Lev",
+	"BHD": "Bahraini Dinar",
+	"BIF": "Burundi Franc",
+	"BMD": "Bermudian Dollar",
+	"BND": "Brunei Dollar",
+	"BOB": "Boliviano",
+	"BOV": "Mvdol",
+	"BRL": "Brazilian Real",
+	"BSD": "Bahamian Dollar",
+	"BTN": "Ngultrum",
+	"BWP": "Pula",
+	"BYN": "Belarusian Ruble",
+	"BZD": "Belize Dollar",
+	"CAD": "Canadian Dollar",
+	"CDF": "Congolese Franc",
+	"CHE": "WIR Euro",
+	"CHF": "Swiss Franc",
+	"CHW": "WIR Franc",
+	"CLF": "Unidad de Fomento",
+	"CLP": "Chilean Peso",
+	"CNY": "Yuan Renminbi",
+	"COP": "Colombian Peso",
+	"COU": "Unidad de Valor Real",
+	"CRC": "Costa Rican Colon",
+	"CUP": "Cuban Peso",
+	"CVE": "Cabo Verde Escudo",
+	"CZK": "Czech Koruna",
+	"DJF": "Djibouti Franc",
+	"DKK": "Danish Krone",
+	"DOP": "Dominican Peso",
+	"DZD": "Algerian Dinar",
+	"EGP": "Egyptian Pound",
+	"ERN": "Nakfa",
+	"ETB": "Ethiopian Birr",
+	"EUR": "Euro",
+	"FJD": "Fiji Dollar",
+	"FKP": "Falkland Islands Pound",
+	"GBP": "Pound Sterling",
+	"GEL": "Lari",
+	"GHS": "Ghana Cedi",
+	"GIP": "Gibraltar Pound",
+	"GMD": "Dalasi",
+	"GNF": "Guinean Franc",
+	"GTQ": "Quetzal",
+	"GYD": "Guyana Dollar",
+	"HKD": "Hong Kong Dollar",
+	"HNL": "Lempira",
+	"HTG": "Gourde",
+	"HUF": "Forint",
+	"IDR": "Rupiah",
+	"ILS": "New Israeli Sheqel",
+	"INR": "Indian Rupee",
+	"IQD": "Iraqi Dinar",
+	"IRR": "Iranian Rial",
+	"ISK": "Iceland Krona",
+	"JMD": "Jamaican Dollar",
+	"JOD": "Jordanian Dinar",
+	"JPY": "Yen",
+	"KES": "Kenyan Shilling",
+	"KGS": "Som",
+	"KHR": "Riel",
+	"KMF": "Comorian Franc",
+	"KPW": "North Korean Won",
+	"KRW": "Won",
+	"KWD": "Kuwaiti Dinar",
+	"KYD": "Cayman Islands Dollar",
+	"KZT": "Tenge",
+	"LAK": "Lao Kip",
+	"LBP": "Lebanese Pound",
+	"LKR": "Sri Lanka Rupee",
+	"LRD": "Liberian Dollar",
+	"LSL": "Loti",
+	"LYD": "Libyan Dinar",
+	"MAD": "Moroccan Dirham",
+	"MDL": "Moldovan Leu",
+	"MGA": "Malagasy Ariary",
+	"MKD": "Denar",
+	"MMK": "Kyat",
+	"MNT": "Tugrik",
+	"MOP": "Pataca",
+	"MRU": "Ouguiya",
+	"MUR": "Mauritius Rupee",
+	"MVR": "Rufiyaa",
+	"MWK": "Malawi Kwacha",
+	"MXN": "Mexican Peso",
+	"MXV": "Mexican Unidad de Inversion (UDI)",
+	"MYR": "Malaysian Ringgit",
+	"MZN": "Mozambique Metical",
+	"NAD": "Namibia Dollar",
+	"NGN": "Naira",
+	"NIO": "Cordoba Oro",
+	"NOK": "Norwegian Krone",
+	"NPR": "Nepalese Rupee",
+	"NZD": "New Zealand Dollar",
+	"OMR": "Rial Omani",
+	"PAB": "Balboa",
+	"PEN": "Sol",
+	"PGK": "Kina",
+	"PHP": "Philippine Peso",
+	"PKR": "Pakistan Rupee",
+	"PLN": "Zloty",
+	"PYG": "Guarani",
+	"QAR": "Qatari Rial",
+	"RON": "Romanian Leu",
+	"RSD": "Serbian Dinar",
+	"RUB": "Russian Ruble",
+	"RWF": "Rwanda Franc",
+	"SAR": "Saudi Riyal",
+	"SBD": "Solomon Islands Dollar",
+	"SCR": "Seychelles Rupee",
+	"SDG": "Sudanese Pound",
+	"SEK": "Swedish Krona",
+	"SGD": "Singapore Dollar",
+	"SHP": "Saint Helena Pound",
+	"SLE": "Leone",
+	"SOS": "Somali Shilling",
+	"SRD": "Surinam Dollar",
+	"SSP": "South Sudanese Pound",
+	"STN": "Dobra",
+	"SVC": "El Salvador Colon",
+	"SYP": "Syrian Pound",
+	"SZL": "Lilangeni",
+	"THB": "Baht",
+	"TJS": "Somoni",
+	"TMT": "Turkmenistan New Manat",
+	"TND": "Tunisian Dinar",
+	"TOP": "Pa'anga",
+	"TRY": "Turkish Lira",
+	"TTD": "Trinidad and Tobago Dollar",
+	"TWD": "New Taiwan Dollar",
+	"TZS": "Tanzanian Shilling",
+	"UAH": "Hryvnia",
+	"UGX": "Uganda Shilling",
+	"USD": "US Dollar",
+	"USN": "US Dollar (Next day)",
+	"UYI": "Uruguay Peso en Unidades Indexadas (UI)",
+	"UYU": "Peso Uruguayo",
+	"UYW": "Unidad Previsional",
+	"UZS": "Uzbekistan Sum",
+	"VED": "Bolívar Soberano",
+	"VES": "Bolívar Soberano",
+	"VND": "Dong",
+	"VUV": "Vatu",
+	"WST": "Tala",
+	"XAF": "CFA Franc BEAC",
+	"XAG": "Silver",
+	"XAU": "Gold",
+	"XBA": "Bond Markets Unit European Composite Unit (EURCO)",
+	"XBB": "Bond Markets Unit European Monetary Unit (E.M.U.-6)",
+	"XBC": "Bond Markets Unit European Unit of Account 9 (E.U.A.-9)",
+	"XBD": "Bond Markets Unit European Unit of Account 17 (E.U.A.-17)",
+	"XCD": "East Caribbean Dollar",
+	"XCG": "Caribbean Guilder",
+	"XDR": "SDR (Special Drawing Right)",
+	"XOF": "CFA Franc BCEAO",
+	"XPD": "Palladium",
+	"XPF": "CFP Franc",
+	"XPT": "Platinum",
+	"XSU": "Sucre",
+	"XUA": "ADB Unit of Account",
+	"YER": "Yemeni Rial",
+	"ZAR": "Rand",
+	"ZMW": "Zambian Kwacha",
+	"ZWG": "Zimbabwe Gold",
+}
+
+// ValidateCurrency checks that code is a catalog 02 (ISO 4217) currency.
+func ValidateCurrency(code string) error {
+	if _, ok := currencies[code]; !ok {
+		return fmt.Errorf("moneda %q no soportada (catálogo 02)", code)
+	}
+	return nil
+}
+
+// ExchangeRateMaxAge is how old the last published rate may be when a day has none, e.g. on
+// weekends and holidays the rate of the last business day applies.
+const ExchangeRateMaxAge = 7 * 24 * time.Hour
+
+// ExchangeRate is the buying and selling rate, in soles, of a currency published by the
+// SBS for a day.
+type ExchangeRate struct {
+	Date     time.Time       `json:"fecha"`
+	Currency string          `json:"moneda"`
+	Buy      decimal.Decimal `json:"compra"`
+	Sell     decimal.Decimal `json:"venta"`
+}
+
+// Validate checks the currency, the date and that both rates are positive.
+func (r ExchangeRate) Validate() error {
+	if r.Currency == CurrencyPEN {
+		return fmt.Errorf("no se registra tipo de cambio para la moneda %s", CurrencyPEN)
+	}
+	if err := ValidateCurrency(r.Currency); err != nil {
+		return err
+	}
+	if r.Date.IsZero() {
+		return fmt.Errorf("la fecha del tipo de cambio es requerida")
+	}
+	if !r.Buy.IsPositive() || !r.Sell.IsPositive() {
+		return fmt.Errorf("el tipo de cambio de %s del %s debe ser mayor a cero", r.Currency, r.Date.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// PENEquivalent is the value in soles of a document issued in foreign currency, at the
+// selling rate of its issue date.
+type PENEquivalent struct {
+	ExchangeRate decimal.Decimal `json:"tipo_cambio"`
+	RateDate     time.Time       `json:"fecha_tipo_cambio"` // Day the rate was published
+	Taxable      decimal.Decimal `json:"base_imponible"`
+	IGV          decimal.Decimal `json:"igv"`
+	Total        decimal.Decimal `json:"total"`
+}
+
+// NewPENEquivalent converts the totals of a document at the selling rate.
+func NewPENEquivalent(rate ExchangeRate, t Totals) *PENEquivalent {
+	e := &PENEquivalent{ExchangeRate: rate.Sell, RateDate: rate.Date}
+	return e.ForTotals(t)
+}
+
+// ForTotals returns the equivalent of other totals at the same rate, e.g. those of a note
+// that modifies the document.
+func (e *PENEquivalent) ForTotals(t Totals) *PENEquivalent {
+	return &PENEquivalent{
+		ExchangeRate: e.ExchangeRate,
+		RateDate:     e.RateDate,
+		Taxable:      e.Convert(t.Taxable),
+		IGV:          e.Convert(t.IGV),
+		Total:        e.Convert(t.Total),
+	}
+}
+
+// Convert returns an amount of the document in soles.
+func (e *PENEquivalent) Convert(amount decimal.Decimal) decimal.Decimal {
+	return Round2(amount.Mul(e.ExchangeRate))
+}
+
+// ConvertFromPEN returns an amount in soles in the currency of the document.
+func (e *PENEquivalent) ConvertFromPEN(amount decimal.Decimal) decimal.Decimal {
+	return Round2(amount.Div(e.ExchangeRate))
+}
+
+// amountPEN returns an amount of the invoice in soles. ok is false when the invoice is in
+// foreign currency and has no PEN equivalent, in which case the amount is returned unchanged.
+func (inv *Invoice) amountPEN(amount decimal.Decimal) (result decimal.Decimal, ok bool) {
+	switch {
+	case inv.Currency == CurrencyPEN:
+		return amount, true
+	case inv.PEN != nil:
+		return inv.PEN.Convert(amount), true
+	default:
+		return amount, false
+	}
+}
+
+// amountFromPEN returns an amount in soles in the currency of the invoice, with the same ok
+// semantics as amountPEN.
+func (inv *Invoice) amountFromPEN(amount decimal.Decimal) (result decimal.Decimal, ok bool) {
+	switch {
+	case inv.Currency == CurrencyPEN:
+		return amount, true
+	case inv.PEN != nil:
+		return inv.PEN.ConvertFromPEN(amount), true
+	default:
+		return amount, false
+	}
+}
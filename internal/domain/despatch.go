@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Remission guide types (catalog 01).
+const (
+	DespatchTypeSender  = "09" // Guía de remisión remitente
+	DespatchTypeCarrier = "31" // Guía de remisión transportista
+)
+
+// DespatchAdvice is an electronic remission guide (GRE) that supports the transfer of goods.
+// The sender issues type 09 guides and the carrier type 31 guides.
+type DespatchAdvice struct {
+	ID          string         `json:"id"`
+	Type        string         `json:"tipo_comprobante"` // 09: Remitente, 31: Transportista
+	Series      string         `json:"serie"`            // T001 for senders, V001 for carriers
+	Number      int            `json:"numero"`
+	IssueDate   time.Time      `json:"fecha_emision"`
+	Issuer      Issuer         `json:"emisor"`
+	Recipient   Recipient      `json:"destinatario"`
+	Shipper     *Recipient     `json:"remitente,omitempty"` // Carrier guides: the sender of the goods
+	Observation string         `json:"observaciones,omitempty"`
+	Shipment    Shipment       `json:"traslado"`
+	Lines       []DespatchLine `json:"items"`
+	Status      string         `json:"estado"`
+	TicketID    string         `json:"ticket_id,omitempty"`
+	StatusError string         `json:"error_sunat,omitempty"`
+}
+
+// DespatchLine is a good transported under a remission guide.
+type DespatchLine struct {
+	Code        string          `json:"codigo,omitempty"`
+	Description string          `json:"descripcion"`
+	Quantity    decimal.Decimal `json:"cantidad"`
+	UnitCode    string          `json:"unidad_medida,omitempty"` // NIU by default
+}
+
+// Unit returns the unit of measure of the line, units (NIU) by default.
+func (l DespatchLine) Unit() string {
+	if l.UnitCode == "" {
+		return "NIU"
+	}
+	return l.UnitCode
+}
+
+// Validate applies the rules of the guide type: sender guides use T series, a catalog 20
+// transfer reason and a transport mode; carrier guides use V series and require the sender
+// of the goods and the vehicle and driver of the carrier.
+func (d *DespatchAdvice) Validate() error {
+	if d.Series == "" || d.Number == 0 {
+		return fmt.Errorf("serie y número son requeridos")
+	}
+	if len(d.Lines) == 0 {
+		return fmt.Errorf("la guía de remisión requiere al menos un bien a trasladar")
+	}
+	for i, line := range d.Lines {
+		if line.Description == "" || !line.Quantity.IsPositive() {
+			return fmt.Errorf("ítem %d: la descripción y una cantidad mayor a cero son requeridas", i+1)
+		}
+	}
+
+	s := &d.Shipment
+	switch d.Type {
+	case DespatchTypeSender:
+		if !strings.HasPrefix(d.Series, "T") {
+			return fmt.Errorf("la serie de una guía remitente debe empezar con T")
+		}
+		if _, ok := transferReasons[s.TransferReason]; !ok {
+			return fmt.Errorf("motivo de traslado %q no soportado", s.TransferReason)
+		}
+		if err := s.validateLoad(d.IssueDate); err != nil {
+			return err
+		}
+		return s.validateMode()
+	case DespatchTypeCarrier:
+		if !strings.HasPrefix(d.Series, "V") {
+			return fmt.Errorf("la serie de una guía transportista debe empezar con V")
+		}
+		if d.Shipper == nil || d.Shipper.DocNum == "" {
+			return fmt.Errorf("la guía transportista requiere los datos del remitente")
+		}
+		if err := s.validateLoad(d.IssueDate); err != nil {
+			return err
+		}
+		return s.validateVehicle()
+	default:
+		return fmt.Errorf("tipo de guía de remisión %q no soportado", d.Type)
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+)
+
+// Operation types (catalog 51).
+const (
+	OperationInternalSale                 = "0101" // Venta interna
+	OperationDetraction                   = "1001" // Operación sujeta a detracción
+	OperationDetractionHydrobiological    = "1002" // Detracción - Recursos hidrobiológicos
+	OperationDetractionPassengerTransport = "1003" // Detracción - Servicios de transporte de pasajeros
+	OperationDetractionFreightTransport   = "1004" // Detracción - Servicios de transporte de carga
+)
+
+// LegendDetraction is the catalog 52 code of the detraction legend.
+const LegendDetraction = "2006"
+
+// Detraction holds the SPOT deposit that the customer must make in the issuer's
+// Banco de la Nación account.
+type Detraction struct {
+	Code             string          `json:"codigo_bien_servicio"` // Catalog 54
+	Percent          decimal.Decimal `json:"porcentaje"`
+	Amount           decimal.Decimal `json:"monto"`                // Always in PEN
+	Account          string          `json:"cuenta_banco_nacion"`  // Defaults to the issuer account
+	PaymentMeansCode string          `json:"medio_pago,omitempty"` // Catalog 59, 001 by default
+}
+
+// detractionGood describes a catalog 54 good or service subject to detraction.
+type detractionGood struct {
+	percent   decimal.Decimal
+	threshold decimal.Decimal // Minimum operation amount in PEN
+}
+
+// detractionGoods holds the catalog 54 codes with their current rates.
+var detractionGoods = map[string]detractionGood{
+	"001": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Azúcar y melaza de caña
+	"003": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Alcohol etílico
+	"004": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Recursos hidrobiológicos
+	"005": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Maíz amarillo duro
+	"007": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Caña de azúcar
+	"008": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Madera
+	"009": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Arena y piedra
+	"010": {percent: decimal.MustParse("15"), threshold: decimal.MustParse("700")},  // Residuos, subproductos, desechos, recortes y desperdicios
+	"011": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Bienes gravados con el IGV por renuncia a la exoneración
+	"012": {percent: decimal.MustParse("12"), threshold: decimal.MustParse("700")},  // Intermediación laboral y tercerización
+	"014": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Carnes y despojos comestibles
+	"016": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Aceite de pescado
+	"017": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Harina, polvo y pellets de pescado
+	"019": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Arrendamiento de bienes muebles
+	"020": {percent: decimal.MustParse("12"), threshold: decimal.MustParse("700")},  // Mantenimiento y reparación de bienes muebles
+	"021": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Movimiento de carga
+	"022": {percent: decimal.MustParse("12"), threshold: decimal.MustParse("700")},  // Otros servicios empresariales
+	"024": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Comisión mercantil
+	"025": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Fabricación de bienes por encargo
+	"026": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Servicio de transporte de personas
+	"027": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("400")},   // Servicio de transporte de carga
+	"030": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Contratos de construcción
+	"031": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Oro gravado con el IGV
+	"032": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Páprika y otros frutos de los géneros capsicum o pimienta
+	"034": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Minerales metálicos no auríferos
+	"035": {percent: decimal.MustParse("1.5"), threshold: decimal.MustParse("700")}, // Bienes exonerados del IGV
+	"036": {percent: decimal.MustParse("1.5"), threshold: decimal.MustParse("700")}, // Oro y demás minerales metálicos exonerados del IGV
+	"037": {percent: decimal.MustParse("12"), threshold: decimal.MustParse("700")},  // Demás servicios gravados con el IGV
+	"039": {percent: decimal.MustParse("10"), threshold: decimal.MustParse("700")},  // Minerales no metálicos
+	"040": {percent: decimal.MustParse("4"), threshold: decimal.MustParse("700")},   // Bien inmueble gravado con IGV
+	"041": {percent: decimal.MustParse("15"), threshold: decimal.MustParse("700")},  // Plomo
+}
+
+// detractionOperationType returns the catalog 51 operation type for a catalog 54 code.
+func detractionOperationType(code string) string {
+	switch code {
+	case "004":
+		return OperationDetractionHydrobiological
+	case DetractionFreightTransport:
+		return OperationDetractionFreightTransport
+	default:
+		return OperationDetraction
+	}
+}
+
+// ApplyDetraction detects whether the invoice is subject to detraction, either because
+// it was requested explicitly or because a line carries a catalog 54 code and the total
+// exceeds the threshold, and completes the percentage, amount, account and operation type.
+// Freight transport services (027) apply the percentage to the referential value of the trip
+// when it exceeds the operation amount. Invoices in foreign currency are compared and
+// charged in soles through their PEN equivalent.
+func (inv *Invoice) ApplyDetraction() error {
+	total, hasPEN := inv.amountPEN(inv.Totals.Total)
+	if inv.Detraction == nil {
+		code := ""
+		for _, line := range inv.Lines {
+			if line.DetractionCode != "" {
+				code = line.DetractionCode
+				break
+			}
+		}
+		good, ok := detractionGoods[code]
+		if !ok || inv.Type != "01" || inv.IsExport() || !total.GreaterThan(good.threshold) {
+			return nil
+		}
+		inv.Detraction = &Detraction{Code: code}
+	}
+
+	d := inv.Detraction
+	good, ok := detractionGoods[d.Code]
+	if !ok {
+		return fmt.Errorf("código de detracción %q no soportado", d.Code)
+	}
+	if inv.Type != "01" {
+		return fmt.Errorf("la detracción solo aplica a facturas")
+	}
+	if inv.IsExport() {
+		return fmt.Errorf("la detracción no aplica a exportaciones")
+	}
+	if d.Percent.IsZero() {
+		d.Percent = good.percent
+	}
+	if d.Account == "" {
+		d.Account = inv.Issuer.DetractionAccount
+	}
+	if d.Account == "" {
+		return fmt.Errorf("se requiere la cuenta del Banco de la Nación para la detracción")
+	}
+	if d.PaymentMeansCode == "" {
+		d.PaymentMeansCode = "001" // Depósito en cuenta
+	}
+	base := total
+	if d.Code == DetractionFreightTransport {
+		var err error
+		if base, err = inv.applyFreightTransport(total); err != nil {
+			return err
+		}
+	}
+	if d.Amount.IsZero() {
+		if !hasPEN {
+			return fmt.Errorf("el tipo de cambio o el monto de la detracción en soles es requerido para comprobantes en %s", inv.Currency)
+		}
+		// The deposit is made in whole soles.
+		d.Amount = percentOf(base, d.Percent).Round(0)
+	}
+
+	if inv.OperationType == "" || inv.OperationType == OperationInternalSale {
+		inv.OperationType = detractionOperationType(d.Code)
+	}
+	return nil
+}
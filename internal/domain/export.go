@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Export operation types (catalog 51).
+const (
+	OperationExportGoods           = "0200" // Exportación de bienes
+	OperationExportServices        = "0201" // Exportación de servicios - prestados íntegramente en el país
+	OperationExportLodging         = "0202" // Exportación de servicios - hospedaje a no domiciliados
+	OperationExportShipping        = "0203" // Exportación de servicios - transporte de navieras
+	OperationExportForeignVessels  = "0204" // Exportación de servicios - a naves y aeronaves de bandera extranjera
+	OperationExportTourPackage     = "0205" // Exportación de servicios - que conforman un paquete turístico
+	OperationExportFreightServices = "0206" // Exportación de servicios - complementarios al transporte de carga
+	OperationExportElectricityZED  = "0207" // Exportación de servicios - suministro de energía eléctrica a sujetos en ZED
+	OperationExportPartiallyAbroad = "0208" // Exportación de servicios - prestados parcialmente en el extranjero
+)
+
+// exportOperationPrefix is shared by every export operation type.
+const exportOperationPrefix = "02"
+
+// exportOperationTypes holds the export operation types of catalog 51.
+var exportOperationTypes = map[string]bool{
+	OperationExportGoods:           true,
+	OperationExportServices:        true,
+	OperationExportLodging:         true,
+	OperationExportShipping:        true,
+	OperationExportForeignVessels:  true,
+	OperationExportTourPackage:     true,
+	OperationExportFreightServices: true,
+	OperationExportElectricityZED:  true,
+	OperationExportPartiallyAbroad: true,
+}
+
+// identityDocTypes maps the document type names accepted by the API to catalog 06 codes.
+var identityDocTypes = map[string]string{
+	"NO_DOMICILIADO":      "0", // Doc. tributario no domiciliado sin RUC
+	"DNI":                 "1",
+	"CE":                  "4", // Carnet de extranjería
+	"RUC":                 "6",
+	"PASAPORTE":           "7",
+	"CDI":                 "A", // Cédula diplomática de identidad
+	"DOC_PAIS_RESIDENCIA": "B", // Doc. de identidad del país de residencia - no domiciliado
+	"TIN":                 "C", // Tax Identification Number
+	"IN":                  "D", // Identification Number
+	"TAM":                 "E", // Tarjeta andina de migración
+	"PTP":                 "F", // Permiso temporal de permanencia
+	"SALVOCONDUCTO":       "G",
+	"CPP":                 "H", // Carné de permiso temporal de permanencia
+}
+
+// nonDomiciledDocTypes holds the catalog 06 codes that identify non-domiciled customers.
+var nonDomiciledDocTypes = map[string]bool{"0": true, "7": true, "A": true, "B": true, "C": true, "D": true, "E": true, "G": true}
+
+// IdentityDocCode returns the catalog 06 code of a recipient document type, which may be
+// given by name (DNI, RUC, PASAPORTE...) or directly as a code. It returns an empty code
+// when the type is empty or unknown.
+func IdentityDocCode(docType string) string {
+	if code, ok := identityDocTypes[strings.ToUpper(docType)]; ok {
+		return code
+	}
+	for _, code := range identityDocTypes {
+		if code == docType {
+			return code
+		}
+	}
+	return ""
+}
+
+// ExportData holds the delivery terms of an export.
+type ExportData struct {
+	Incoterm           string `json:"incoterm,omitempty"` // e.g. FOB, CIF, EXW
+	DestinationCountry string `json:"pais_destino"`       // ISO 3166-1 alpha-2
+	DestinationAddress string `json:"direccion_destino,omitempty"`
+}
+
+// IsExport reports whether the invoice is an export of goods or services.
+func (inv *Invoice) IsExport() bool {
+	return strings.HasPrefix(inv.OperationType, exportOperationPrefix)
+}
+
+// ValidateExport applies the SUNAT rules of exports: only facturas, every line with
+// affectation 40, a non-domiciled customer and, for goods, the delivery terms and
+// destination country. Lines without affectation default to 40 in exports.
+func (inv *Invoice) ValidateExport() error {
+	if !inv.IsExport() {
+		for i, line := range inv.Lines {
+			if line.AffectationCode == AffectationExport {
+				return fmt.Errorf("ítem %d: la afectación %s requiere un tipo de operación de exportación", i+1, AffectationExport)
+			}
+		}
+		return nil
+	}
+
+	if !exportOperationTypes[inv.OperationType] {
+		return fmt.Errorf("tipo de operación de exportación %q no soportado", inv.OperationType)
+	}
+	if inv.Type != "01" {
+		return fmt.Errorf("las exportaciones solo se sustentan con factura")
+	}
+	for i := range inv.Lines {
+		line := &inv.Lines[i]
+		if line.AffectationCode == "" {
+			line.AffectationCode = AffectationExport
+		}
+		if line.AffectationCode != AffectationExport {
+			return fmt.Errorf("ítem %d: en una exportación la afectación debe ser %s", i+1, AffectationExport)
+		}
+	}
+	if inv.Detraction != nil || inv.Perception != nil {
+		return fmt.Errorf("una exportación no está sujeta a detracción ni percepción")
+	}
+
+	docCode := IdentityDocCode(inv.Recipient.DocType)
+	if docCode == "" {
+		return fmt.Errorf("tipo de documento del cliente %q no reconocido", inv.Recipient.DocType)
+	}
+	switch inv.OperationType {
+	case OperationExportElectricityZED:
+		// The customers are domiciled in the ZED and identified by RUC.
+	case OperationExportLodging:
+		if docCode != "7" && docCode != "B" {
+			return fmt.Errorf("el huésped no domiciliado debe identificarse con pasaporte o documento de su país de residencia")
+		}
+	default:
+		if !nonDomiciledDocTypes[docCode] {
+			return fmt.Errorf("el cliente de una exportación debe ser no domiciliado, tipo de documento %q no permitido", inv.Recipient.DocType)
+		}
+	}
+
+	if inv.OperationType == OperationExportGoods {
+		e := inv.Export
+		if e == nil || e.Incoterm == "" || e.DestinationCountry == "" {
+			return fmt.Errorf("la exportación de bienes requiere el incoterm y el país de destino")
+		}
+	}
+	if e := inv.Export; e != nil && strings.EqualFold(e.DestinationCountry, "PE") {
+		return fmt.Errorf("el país de destino de una exportación no puede ser Perú")
+	}
+	return nil
+}
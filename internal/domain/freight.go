@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+)
+
+// DetractionFreightTransport is the catalog 54 code of freight transport services.
+const DetractionFreightTransport = "027"
+
+// FreightTransport holds the trip of a freight transport service subject to detraction. The
+// referential values are calculated with the referential value per TM of the route.
+type FreightTransport struct {
+	Origin               Location        `json:"origen"`
+	Destination          Location        `json:"destino"`
+	TripDetail           string          `json:"detalle_viaje,omitempty"`
+	UnitReferentialValue decimal.Decimal `json:"valor_referencial_tm"`             // PEN per TM of the route
+	EffectiveLoad        decimal.Decimal `json:"carga_efectiva,omitzero"`          // TM, the nominal load when unknown
+	NominalLoad          decimal.Decimal `json:"carga_util_nominal"`               // TM the vehicle can carry
+	ReferentialValue     decimal.Decimal `json:"valor_referencial"`                // Of the trip, on the effective load
+	EffectiveLoadValue   decimal.Decimal `json:"valor_referencial_carga_efectiva"` // Unit value x effective load
+	NominalLoadValue     decimal.Decimal `json:"valor_referencial_carga_nominal"`  // Unit value x nominal load
+}
+
+// validate checks the route and the loads of the trip.
+func (f *FreightTransport) validate() error {
+	if err := f.Origin.validate("origen"); err != nil {
+		return err
+	}
+	if err := f.Destination.validate("destino"); err != nil {
+		return err
+	}
+	if !f.UnitReferentialValue.IsPositive() {
+		return fmt.Errorf("el valor referencial por TM de la ruta debe ser mayor a cero")
+	}
+	if !f.NominalLoad.IsPositive() {
+		return fmt.Errorf("la carga útil nominal debe ser mayor a cero")
+	}
+	if f.EffectiveLoad.IsNegative() || f.EffectiveLoad.GreaterThan(f.NominalLoad) {
+		return fmt.Errorf("la carga efectiva debe estar entre 0 y la carga útil nominal (%s TM)", f.NominalLoad)
+	}
+	return nil
+}
+
+// calculate computes the referential values of the trip. The referential value of the service
+// uses the effective load, or the nominal load when the effective load is unknown.
+func (f *FreightTransport) calculate() {
+	load := f.EffectiveLoad
+	if load.IsZero() {
+		load = f.NominalLoad
+	}
+	f.EffectiveLoadValue = Round2(f.UnitReferentialValue.Mul(load))
+	f.NominalLoadValue = Round2(f.UnitReferentialValue.Mul(f.NominalLoad))
+	f.ReferentialValue = f.EffectiveLoadValue
+}
+
+// applyFreightTransport validates the trip of a freight transport detraction and returns the
+// detraction base: the operation amount in soles or the referential value, whichever is greater.
+func (inv *Invoice) applyFreightTransport(total decimal.Decimal) (decimal.Decimal, error) {
+	f := inv.Freight
+	if f == nil {
+		return decimal.Zero, fmt.Errorf("la detracción del servicio de transporte de carga requiere los datos del viaje")
+	}
+	if inv.Shipment != nil {
+		return decimal.Zero, fmt.Errorf("la detracción del servicio de transporte de carga no aplica a una factura guía")
+	}
+	if err := f.validate(); err != nil {
+		return decimal.Zero, err
+	}
+	f.calculate()
+	return decimal.Max(total, f.ReferentialValue), nil
+}
@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DebitNoteTypeInterest is the catalog 10 code of late-payment interest (intereses por mora).
+const DebitNoteTypeInterest = "01"
+
+// InterestPolicy is the late-payment interest an issuer charges on overdue installments.
+type InterestPolicy struct {
+	RUC        string          `json:"ruc"`
+	AnnualRate decimal.Decimal `json:"tasa_efectiva_anual"` // Effective annual rate (TEA) in percent
+	GraceDays  int             `json:"dias_gracia"`         // Days after the due date before interest is charged
+	MinAmount  decimal.Decimal `json:"monto_minimo"`        // Interest below this amount is not charged
+}
+
+// Interest returns the compound interest of an amount over the given days, using a
+// 360-day year as the financial system does. The fractional power is computed in float64 and
+// the factor, rounded to 10 decimals, is then applied exactly.
+func (p InterestPolicy) Interest(amount decimal.Decimal, days int) decimal.Decimal {
+	factor := math.Pow(1+p.AnnualRate.Float64()/100, float64(days)/360) - 1
+	return Round2(amount.Mul(decimal.NewFromFloat(factor)))
+}
+
+// InterestPolicyRegistry holds the interest policy configured for each issuer.
+type InterestPolicyRegistry struct {
+	mu       sync.RWMutex
+	policies map[string]InterestPolicy
+}
+
+// NewInterestPolicyRegistry creates an empty registry.
+func NewInterestPolicyRegistry() *InterestPolicyRegistry {
+	return &InterestPolicyRegistry{policies: make(map[string]InterestPolicy)}
+}
+
+// Set validates and stores the policy of an issuer, replacing the previous one.
+func (r *InterestPolicyRegistry) Set(p InterestPolicy) error {
+	if p.RUC == "" {
+		return fmt.Errorf("el RUC del emisor es requerido")
+	}
+	if !p.AnnualRate.IsPositive() || p.GraceDays < 0 || p.MinAmount.IsNegative() {
+		return fmt.Errorf("la tasa debe ser mayor a cero y los días de gracia y el monto mínimo no pueden ser negativos")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies[p.RUC] = p
+	return nil
+}
+
+// Lookup returns the policy of the issuer, if any.
+func (r *InterestPolicyRegistry) Lookup(ruc string) (InterestPolicy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.policies[ruc]
+	return p, ok
+}
+
+// All returns the policies of every issuer.
+func (r *InterestPolicyRegistry) All() []InterestPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]InterestPolicy, 0, len(r.policies))
+	for _, p := range r.policies {
+		result = append(result, p)
+	}
+	return result
+}
+
+// InterestItem is the interest charged on an overdue installment for a period.
+type InterestItem struct {
+	Installment int             `json:"cuota"` // 1-based, as in Cuota001
+	DueDate     time.Time       `json:"fecha_vencimiento"`
+	Amount      decimal.Decimal `json:"monto"`
+	From        time.Time       `json:"desde"`
+	To          time.Time       `json:"hasta"`
+	Days        int             `json:"dias"`
+	Interest    decimal.Decimal `json:"interes"`
+}
+
+// DebitNoteDraft is an interest debit note generated automatically that an operator
+// reviews before issuing it.
+type DebitNoteDraft struct {
+	ID          string         `json:"id"`
+	InvoiceID   string         `json:"id_factura"`
+	Items       []InterestItem `json:"cuotas"`
+	DebitNote   DebitNote      `json:"nota_debito"`
+	Status      string         `json:"estado"` // BORRADOR, EMITIDO, DESCARTADO
+	CreatedAt   time.Time      `json:"fecha_generacion"`
+	DebitNoteID string         `json:"id_nota_debito,omitempty"`
+}
+
+// OutstandingInstallments returns the credit installments still owed on an invoice: those of
+// the latest type 13 credit note that corrected them, or else those issued, reduced by the
+// credit notes issued since, starting from the last installment. Rejected notes are ignored,
+// and the installments of a fully credited invoice are all zero.
+func OutstandingInstallments(inv *Invoice, credits []*CreditNote) []Installment {
+	if inv.PaymentTerms == nil || inv.PaymentTerms.Method != PaymentCredit {
+		return nil
+	}
+
+	installments := inv.PaymentTerms.Installments
+	var corrected time.Time
+	for _, cn := range credits {
+		if cn.Status == "RECHAZADO" || cn.DiscrepancyResponse.TypeCode != CreditNoteTypeInstallments || cn.PaymentTerms == nil {
+			continue
+		}
+		if corrected.IsZero() || !cn.IssueDate.Before(corrected) {
+			corrected = cn.IssueDate
+			installments = cn.PaymentTerms.Installments
+		}
+	}
+
+	var credited decimal.Decimal
+	for _, cn := range credits {
+		if cn.Status != "RECHAZADO" && !cn.IssueDate.Before(corrected) {
+			credited = credited.Add(cn.Totals.Total)
+		}
+	}
+	result := slices.Clone(installments)
+	for i := len(result) - 1; i >= 0 && credited.IsPositive(); i-- {
+		reduced := decimal.Min(result[i].Amount, credited)
+		result[i].Amount = result[i].Amount.Sub(reduced)
+		credited = credited.Sub(reduced)
+	}
+	return result
+}
+
+// NewInterestDraft computes the interest of the overdue installments still owed on a credit
+// invoice up to asOf. chargedUntil holds, by installment, the last day charged by issued
+// drafts, and stopped the installments no longer charged, e.g. because they were paid.
+// It returns nil when there is no interest to charge.
+func NewInterestDraft(inv *Invoice, installments []Installment, policy InterestPolicy, chargedUntil map[int]time.Time, stopped map[int]bool, asOf time.Time) *DebitNoteDraft {
+	today := calendarDay(asOf)
+	var items []InterestItem
+	for i, c := range installments {
+		if stopped[i+1] || !c.Amount.IsPositive() {
+			continue
+		}
+		due := calendarDay(c.DueDate)
+		if !today.After(due.AddDate(0, 0, policy.GraceDays)) {
+			continue
+		}
+		from := due
+		if charged, ok := chargedUntil[i+1]; ok && charged.After(from) {
+			from = calendarDay(charged)
+		}
+		days := int(today.Sub(from).Hours() / 24)
+		if days <= 0 {
+			continue
+		}
+		items = append(items, InterestItem{
+			Installment: i + 1,
+			DueDate:     due,
+			Amount:      c.Amount,
+			From:        from,
+			To:          today,
+			Days:        days,
+			Interest:    policy.Interest(c.Amount, days),
+		})
+	}
+
+	var total decimal.Decimal
+	for _, item := range items {
+		total = total.Add(item.Interest)
+	}
+	if len(items) == 0 || !total.IsPositive() || total.LessThan(policy.MinAmount) {
+		return nil
+	}
+
+	dn := DebitNote{
+		Type:      "08",
+		Currency:  inv.Currency,
+		Issuer:    inv.Issuer,
+		Recipient: inv.Recipient,
+		DiscrepancyResponse: DiscrepancyResponse{
+			ReferenceID: fmt.Sprintf("%s-%d", inv.Series, inv.Number),
+			DocType:     inv.Type,
+			TypeCode:    DebitNoteTypeInterest,
+			Description: "Intereses por mora",
+		},
+	}
+	for _, item := range items {
+		dn.Lines = append(dn.Lines, InvoiceLine{
+			Description: fmt.Sprintf("Intereses por mora de la cuota %03d del %s al %s (%d días)",
+				item.Installment, item.From.Format("02/01/2006"), item.To.Format("02/01/2006"), item.Days),
+			Quantity:        decimal.NewFromInt(1),
+			UnitPrice:       item.Interest,
+			AffectationCode: AffectationUnaffected, // Late payment interest is not part of the taxed sale value
+		})
+	}
+
+	return &DebitNoteDraft{
+		InvoiceID: inv.ID,
+		Items:     items,
+		DebitNote: dn,
+		Status:    "BORRADOR",
+		CreatedAt: asOf,
+	}
+}
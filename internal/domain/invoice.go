@@ -1,51 +1,97 @@
 package domain
 
-import "time"
+import (
+	"FacturacionSunat/pkg/decimal"
+	"time"
+)
 
 // Issuer represents the company issuing the invoice.
 type Issuer struct {
-	RUC      string `json:"ruc"`
-	Name     string `json:"razon_social"`
-	Address  string `json:"direccion"`
+	RUC               string `json:"ruc"`
+	Name              string `json:"razon_social"`
+	Address           string `json:"direccion"`
+	Ubigeo            string `json:"ubigeo,omitempty"`            // INEI district code, e.g. for the Amazonía legends
+	Regime            string `json:"regimen,omitempty"`           // GENERAL, MYPE_RESTAURANTE
+	DetractionAccount string `json:"cuenta_detraccion,omitempty"` // Banco de la Nación account
+	PerceptionAgent   bool   `json:"agente_percepcion,omitempty"`
+	RetentionAgent    bool   `json:"agente_retencion,omitempty"`
 }
 
 // Recipient represents the customer receiving the invoice.
 type Recipient struct {
-	DocType string `json:"tipo_doc"` // DNI, RUC, CE
-	DocNum  string `json:"num_doc"`
-	Name    string `json:"nombre"`
+	DocType          string `json:"tipo_doc"` // DNI, RUC, CE
+	DocNum           string `json:"num_doc"`
+	Name             string `json:"nombre"`
+	PerceptionRegime string `json:"regimen_percepcion,omitempty"` // Catalog 53: 51, 52 or 53
+	RetentionAgent   bool   `json:"agente_retencion,omitempty"`
 }
 
 // InvoiceLine represents a single item line in the invoice.
 type InvoiceLine struct {
-	ID          string  `json:"id"`
-	Code        string  `json:"codigo"`
-	Description string  `json:"descripcion"`
-	Quantity    float64 `json:"cantidad"`
-	UnitPrice   float64 `json:"valor_unitario"`
-	TotalValue  float64 `json:"valor_total"`
-	IGV         float64 `json:"igv"`
+	ID               string            `json:"id"`
+	Code             string            `json:"codigo"`
+	Description      string            `json:"descripcion"`
+	Quantity         decimal.Decimal   `json:"cantidad"`
+	UnitPrice        decimal.Decimal   `json:"valor_unitario"`
+	TotalValue       decimal.Decimal   `json:"valor_total"`
+	IGV              decimal.Decimal   `json:"igv"` // IGV or IVAP of the line
+	AllowanceCharges []AllowanceCharge `json:"descuentos_cargos,omitempty"`
+	AffectationCode  string            `json:"tipo_afectacion,omitempty"`   // Catalog 07, 10 by default
+	DetractionCode   string            `json:"codigo_detraccion,omitempty"` // Catalog 54
+	Properties       []ItemProperty    `json:"propiedades,omitempty"`       // Catalog 55
+	IsService        bool              `json:"es_servicio,omitempty"`       // Service instead of goods
 }
 
 // Totals represents the monetary totals for the invoice.
 type Totals struct {
-	Gross      float64 `json:"gravado"`
-	IGV        float64 `json:"igv"`
-	Total      float64 `json:"total"`
+	Gross        decimal.Decimal `json:"gravado"`              // Sum of the line values, free transfers excluded
+	Taxable      decimal.Decimal `json:"base_imponible"`       // IGV base adjusted by the global allowances/charges that affect it
+	IVAPBase     decimal.Decimal `json:"base_ivap,omitzero"`   // IVAP base
+	Exonerated   decimal.Decimal `json:"exonerado,omitzero"`   // Exonerated operations
+	Unaffected   decimal.Decimal `json:"inafecto,omitzero"`    // Unaffected operations
+	Exported     decimal.Decimal `json:"exportacion,omitzero"` // Exports
+	Free         decimal.Decimal `json:"gratuito,omitzero"`    // Referential value of free transfers
+	IGV          decimal.Decimal `json:"igv"`
+	IVAP         decimal.Decimal `json:"ivap,omitzero"`
+	FreeTax      decimal.Decimal `json:"igv_gratuito,omitzero"` // IGV of taxed free transfers, not charged
+	TaxInclusive decimal.Decimal `json:"precio_venta"`          // Bases + IGV + IVAP
+	Allowances   decimal.Decimal `json:"descuentos"`            // Discounts that do not affect the taxable base
+	Charges      decimal.Decimal `json:"cargos"`                // Charges that do not affect the taxable base
+	Prepaid      decimal.Decimal `json:"anticipos"`             // Prepayments deducted, IGV included
+	Total        decimal.Decimal `json:"total"`
 }
 
 // Invoice represents the main electronic invoice document.
 type Invoice struct {
-	ID          string        `json:"id"`
-	Type        string        `json:"tipo_comprobante"` // 01: Factura, 03: Boleta
-	Series      string        `json:"serie"`
-	Number      int           `json:"numero"`
-	IssueDate   time.Time     `json:"fecha_emision"`
-	Currency    string        `json:"moneda"` // PEN, USD
-	Issuer      Issuer        `json:"emisor"`
-	Recipient   Recipient     `json:"receptor"`
-	Lines       []InvoiceLine `json:"items"`
-	Totals      Totals        `json:"totales"`
-	Status      string        `json:"estado"` // (aceptado, rechazado, etc.)
-	TicketID    string        `json:"ticket_id,omitempty"` // SUNAT ticket ID for tracking
-}
\ No newline at end of file
+	ID               string            `json:"id"`
+	Type             string            `json:"tipo_comprobante"`         // 01: Factura, 03: Boleta
+	OperationType    string            `json:"tipo_operacion,omitempty"` // Catalog 51, 0101 by default
+	Series           string            `json:"serie"`
+	Number           int               `json:"numero"`
+	IssueDate        time.Time         `json:"fecha_emision"`
+	Currency         string            `json:"moneda"` // Catalog 02, PEN by default
+	Issuer           Issuer            `json:"emisor"`
+	Recipient        Recipient         `json:"receptor"`
+	Lines            []InvoiceLine     `json:"items"`
+	AllowanceCharges []AllowanceCharge `json:"descuentos_cargos,omitempty"` // Global discounts and charges
+	IsPrepayment     bool              `json:"es_anticipo,omitempty"`       // Invoice issued for a prepayment
+	PrepaidBalance   decimal.Decimal   `json:"saldo_anticipo,omitzero"`     // Prepayment amount not yet deducted
+	Prepayments      []Prepayment      `json:"anticipos,omitempty"`         // Prepayments deducted from this invoice
+	Detraction       *Detraction       `json:"detraccion,omitempty"`
+	Perception       *Perception       `json:"percepcion,omitempty"`
+	PaymentTerms     *PaymentTerms     `json:"condiciones_pago,omitempty"`
+	Export           *ExportData       `json:"exportacion,omitempty"`      // Delivery terms of exports
+	Shipment         *Shipment         `json:"guia_remision,omitempty"`    // Transport data of a factura guía
+	Freight          *FreightTransport `json:"transporte_carga,omitempty"` // Trip of a freight transport detraction
+	Legends          []Legend          `json:"leyendas,omitempty"`
+	Totals           Totals            `json:"totales"`
+	PEN              *PENEquivalent    `json:"equivalente_pen,omitempty"` // Totals in soles of foreign currency invoices
+	Status           string            `json:"estado"`                    // (aceptado, rechazado, etc.)
+	TicketID         string            `json:"ticket_id,omitempty"`       // SUNAT ticket ID for tracking
+}
+
+// Legend is a catalog 52 legend printed on the document.
+type Legend struct {
+	Code  string `json:"codigo"` // Catalog 52
+	Value string `json:"descripcion"`
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ItemPropertyKind tells where the value of a catalog 55 property is reported.
+type ItemPropertyKind int
+
+// Kinds of catalog 55 properties.
+const (
+	PropertyText      ItemPropertyKind = iota // Free text value
+	PropertyStartDate                         // Start date of the usability period, YYYY-MM-DD
+	PropertyEndDate                           // End date of the usability period, YYYY-MM-DD
+	PropertyStartTime                         // Start time of the usability period, HH:MM:SS
+	PropertyDuration                          // Duration of the usability period, in days
+)
+
+// itemPropertyType describes a catalog 55 property.
+type itemPropertyType struct {
+	name string
+	kind ItemPropertyKind
+}
+
+// itemProperties holds the supported catalog 55 codes.
+var itemProperties = map[string]itemPropertyType{
+	"3001": {"Detracciones: Recursos Hidrobiológicos - Matrícula de la embarcación", PropertyText},
+	"3002": {"Detracciones: Recursos Hidrobiológicos - Nombre de la embarcación", PropertyText},
+	"3003": {"Detracciones: Recursos Hidrobiológicos - Tipo y cantidad de especie vendida", PropertyText},
+	"3004": {"Detracciones: Recursos Hidrobiológicos - Lugar de descarga", PropertyText},
+	"3005": {"Detracciones: Recursos Hidrobiológicos - Fecha de descarga", PropertyStartDate},
+	"3050": {"Transporte Terrestre - Número de asiento", PropertyText},
+	"3051": {"Transporte Terrestre - Información de manifiesto de pasajeros", PropertyText},
+	"3052": {"Transporte Terrestre - Número de documento de identidad del pasajero", PropertyText},
+	"3053": {"Transporte Terrestre - Tipo de documento de identidad del pasajero", PropertyText},
+	"3054": {"Transporte Terrestre - Nombres y apellidos del pasajero", PropertyText},
+	"3055": {"Transporte Terrestre - Ciudad o lugar de destino - Ubigeo", PropertyText},
+	"3056": {"Transporte Terrestre - Ciudad o lugar de destino - Dirección detallada", PropertyText},
+	"3057": {"Transporte Terrestre - Ciudad o lugar de origen - Ubigeo", PropertyText},
+	"3058": {"Transporte Terrestre - Ciudad o lugar de origen - Dirección detallada", PropertyText},
+	"3059": {"Transporte Terrestre - Fecha de inicio programado", PropertyStartDate},
+	"3060": {"Transporte Terrestre - Hora de inicio programado", PropertyStartTime},
+	"4000": {"Beneficio Hospedajes: Código País de emisión del pasaporte", PropertyText},
+	"4001": {"Beneficio Hospedajes: Código País de residencia del sujeto no domiciliado", PropertyText},
+	"4002": {"Beneficio Hospedajes: Fecha de ingreso al país", PropertyStartDate},
+	"4003": {"Beneficio Hospedajes: Fecha de ingreso al establecimiento", PropertyStartDate},
+	"4004": {"Beneficio Hospedajes: Fecha de salida del establecimiento", PropertyEndDate},
+	"4005": {"Beneficio Hospedajes: Número de días de permanencia", PropertyDuration},
+	"4006": {"Beneficio Hospedajes: Fecha de consumo", PropertyStartDate},
+	"4007": {"Beneficio Hospedajes: Nombres y apellidos del huésped", PropertyText},
+	"4008": {"Beneficio Hospedajes: Tipo de documento de identidad del huésped", PropertyText},
+	"4009": {"Beneficio Hospedajes: Número de documento de identidad del huésped", PropertyText},
+	"7000": {"Gastos Art. 37 Renta: Número de Placa", PropertyText},
+}
+
+// ItemProperty is an additional property of a line (catalog 55), such as the vehicle plate of
+// a fuel sale or the check-in date of a hotel guest.
+type ItemProperty struct {
+	Code  string `json:"codigo"` // Catalog 55
+	Value string `json:"valor"`  // Text, date YYYY-MM-DD, time HH:MM:SS or days, depending on the code
+}
+
+// Name returns the catalog 55 description of the property.
+func (p ItemProperty) Name() string {
+	return itemProperties[p.Code].name
+}
+
+// Kind returns where the value of the property is reported.
+func (p ItemProperty) Kind() ItemPropertyKind {
+	return itemProperties[p.Code].kind
+}
+
+// validate checks the code and the format its kind requires of the value.
+func (p ItemProperty) validate() error {
+	t, ok := itemProperties[p.Code]
+	if !ok {
+		return fmt.Errorf("propiedad adicional %q no soportada", p.Code)
+	}
+	if p.Value == "" {
+		return fmt.Errorf("la propiedad adicional %s requiere un valor", p.Code)
+	}
+	switch t.kind {
+	case PropertyStartDate, PropertyEndDate:
+		if _, err := time.Parse("2006-01-02", p.Value); err != nil {
+			return fmt.Errorf("la propiedad adicional %s debe ser una fecha AAAA-MM-DD", p.Code)
+		}
+	case PropertyStartTime:
+		if _, err := time.Parse("15:04:05", p.Value); err != nil {
+			return fmt.Errorf("la propiedad adicional %s debe ser una hora HH:MM:SS", p.Code)
+		}
+	case PropertyDuration:
+		if days, err := strconv.Atoi(p.Value); err != nil || days <= 0 {
+			return fmt.Errorf("la propiedad adicional %s debe ser un número de días mayor a cero", p.Code)
+		}
+	}
+	return nil
+}
+
+// validateProperties checks the catalog 55 properties of the line.
+func (l *InvoiceLine) validateProperties() error {
+	for _, p := range l.Properties {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
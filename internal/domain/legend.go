@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/numletras"
+	"fmt"
+)
+
+// Catalog 52 legend codes attached by the legend rules.
+const (
+	LegendAmountInWords  = "1000" // Monto en letras
+	LegendFreeTransfer   = "1002" // Transferencia gratuita
+	LegendAmazonGoods    = "2001" // Bienes transferidos en la Amazonía
+	LegendAmazonServices = "2002" // Servicios prestados en la Amazonía
+	LegendIVAP           = "2007" // Operación sujeta al IVAP
+)
+
+// legends holds the catalog 52 codes with their default text.
+var legends = map[string]string{
+	LegendAmountInWords:  "", // Generated from the total, see amountInWords
+	LegendFreeTransfer:   "TRANSFERENCIA GRATUITA DE UN BIEN Y/O SERVICIO PRESTADO GRATUITAMENTE",
+	LegendPerception:     "COMPROBANTE DE PERCEPCIÓN",
+	LegendAmazonGoods:    "BIENES TRANSFERIDOS EN LA AMAZONÍA REGIÓN SELVA PARA SER CONSUMIDOS EN LA MISMA",
+	LegendAmazonServices: "SERVICIOS PRESTADOS EN LA AMAZONÍA REGIÓN SELVA PARA SER CONSUMIDOS EN LA MISMA",
+	"2003":               "CONTRATOS DE CONSTRUCCIÓN EJECUTADOS EN LA AMAZONÍA REGIÓN SELVA",
+	"2004":               "Agencia de Viaje - Paquete turístico",
+	"2005":               "Venta realizada por emisor itinerante",
+	LegendDetraction:     "Operación sujeta a detracción",
+	LegendIVAP:           "Operación sujeta al IVAP",
+	"2008":               "VENTA EXONERADA DEL IGV-ISC-IPM. PROHIBIDA LA VENTA FUERA DE LA ZONA COMERCIAL DE TACNA",
+	"2010":               "Restitución Simplificado de Derechos Arancelarios",
+}
+
+// amazonDepartments holds the INEI department codes fully included in the Amazonía for the
+// IGV exoneration: Amazonas, Loreto, Madre de Dios, San Martín and Ucayali.
+var amazonDepartments = map[string]bool{"01": true, "16": true, "17": true, "22": true, "25": true}
+
+// legendContext is the content of a document the legend rules evaluate.
+type legendContext struct {
+	currency   string
+	issuer     Issuer
+	lines      []InvoiceLine
+	totals     Totals
+	detraction *Detraction
+	perception *Perception
+}
+
+// legendRule returns the catalog 52 codes a document requires.
+type legendRule func(c legendContext) []string
+
+// legendRules are evaluated in order on every document.
+var legendRules = []legendRule{
+	// Documents made only of free transfers.
+	func(c legendContext) []string {
+		if c.totals.Free.IsPositive() && c.totals.TaxInclusive.IsZero() {
+			return []string{LegendFreeTransfer}
+		}
+		return nil
+	},
+	func(c legendContext) []string {
+		if c.perception != nil {
+			return []string{LegendPerception}
+		}
+		return nil
+	},
+	// Exonerated goods and services of an issuer located in the Amazonía.
+	func(c legendContext) []string {
+		if len(c.issuer.Ubigeo) < 2 || !amazonDepartments[c.issuer.Ubigeo[:2]] {
+			return nil
+		}
+		var codes []string
+		for _, line := range c.lines {
+			if line.TaxScheme() != TaxSchemeExonerated || line.IsFree() {
+				continue
+			}
+			if line.IsService {
+				codes = append(codes, LegendAmazonServices)
+			} else {
+				codes = append(codes, LegendAmazonGoods)
+			}
+		}
+		return codes
+	},
+	func(c legendContext) []string {
+		if c.detraction != nil {
+			return []string{LegendDetraction}
+		}
+		return nil
+	},
+	func(c legendContext) []string {
+		if c.totals.IVAPBase.IsPositive() {
+			return []string{LegendIVAP}
+		}
+		return nil
+	},
+}
+
+// resolveLegends validates the manual legends, completing their default text, and appends
+// the legends the rules require. A manual legend replaces the automatic one with its code.
+func resolveLegends(manual []Legend, c legendContext) ([]Legend, error) {
+	var result []Legend
+	seen := make(map[string]bool)
+	for _, l := range manual {
+		text, ok := legends[l.Code]
+		if !ok {
+			return nil, fmt.Errorf("leyenda %q no soportada", l.Code)
+		}
+		if seen[l.Code] {
+			return nil, fmt.Errorf("la leyenda %s está repetida", l.Code)
+		}
+		if l.Value == "" {
+			l.Value = text
+		}
+		if l.Value == "" {
+			return nil, fmt.Errorf("la leyenda %s requiere un texto", l.Code)
+		}
+		seen[l.Code] = true
+		result = append(result, l)
+	}
+	if !seen[LegendAmountInWords] {
+		if l, ok := amountInWords(c); ok {
+			seen[LegendAmountInWords] = true
+			result = append(result, l)
+		}
+	}
+	for _, rule := range legendRules {
+		for _, code := range rule(c) {
+			if !seen[code] {
+				seen[code] = true
+				result = append(result, Legend{Code: code, Value: legends[code]})
+			}
+		}
+	}
+	return result, nil
+}
+
+// amountInWords returns the legend with the total of the document in words. The legend is only
+// recommended in the XML, so it is left out for currencies without a name in words.
+func amountInWords(c legendContext) (Legend, bool) {
+	words, err := numletras.Amount(c.totals.Total, c.currency)
+	if err != nil {
+		return Legend{}, false
+	}
+	return Legend{Code: LegendAmountInWords, Value: "SON: " + words}, true
+}
+
+// ApplyLegends attaches the legends the invoice requires to the manual ones. The totals,
+// detraction and perception must be applied first.
+func (inv *Invoice) ApplyLegends() error {
+	result, err := resolveLegends(inv.Legends, legendContext{
+		currency:   inv.Currency,
+		issuer:     inv.Issuer,
+		lines:      inv.Lines,
+		totals:     inv.Totals,
+		detraction: inv.Detraction,
+		perception: inv.Perception,
+	})
+	if err != nil {
+		return err
+	}
+	inv.Legends = result
+	return nil
+}
+
+// ApplyLegends attaches the legends the credit note requires to the manual ones.
+func (cn *CreditNote) ApplyLegends() error {
+	result, err := resolveLegends(cn.Legends, legendContext{currency: cn.Currency, issuer: cn.Issuer, lines: cn.Lines, totals: cn.Totals})
+	if err != nil {
+		return err
+	}
+	cn.Legends = result
+	return nil
+}
+
+// ApplyLegends attaches the legends the debit note requires to the manual ones.
+func (dn *DebitNote) ApplyLegends() error {
+	result, err := resolveLegends(dn.Legends, legendContext{currency: dn.Currency, issuer: dn.Issuer, lines: dn.Lines, totals: dn.Totals})
+	if err != nil {
+		return err
+	}
+	dn.Legends = result
+	return nil
+}
+
+// ApplyLegends attaches the legends the purchase settlement requires to the manual ones.
+func (ps *PurchaseSettlement) ApplyLegends() error {
+	result, err := resolveLegends(ps.Legends, legendContext{currency: ps.Currency, issuer: ps.Issuer, lines: ps.Lines, totals: ps.Totals})
+	if err != nil {
+		return err
+	}
+	ps.Legends = result
+	return nil
+}
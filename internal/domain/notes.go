@@ -4,9 +4,21 @@ import "time"
 
 // DiscrepancyResponse describes the reason for a credit/debit note.
 type DiscrepancyResponse struct {
-	ReferenceID string `json:"nro_comprobante_afectado"` // e.g., F001-123
-	TypeCode    string `json:"codigo_motivo"`            // Catalog 09 for Credit, 10 for Debit
-	Description string `json:"descripcion_motivo"`
+	ReferenceID string    `json:"nro_comprobante_afectado"`            // e.g., F001-123
+	DocType     string    `json:"tipo_comprobante_afectado,omitempty"` // 01: Factura, 03: Boleta
+	TypeCode    string    `json:"codigo_motivo"`                       // Catalog 09 for Credit, 10 for Debit
+	Description string    `json:"descripcion_motivo"`
+	IssueDate   time.Time `json:"fecha_emision_afectado,omitzero"` // Resolved from the affected document
+}
+
+// TaxDate returns the date whose tax rates apply to a note: the issue date of the affected
+// document, so that the note reverses the tax at the rate charged, or the note's own date
+// when the affected document is unknown.
+func (d DiscrepancyResponse) TaxDate(noteDate time.Time) time.Time {
+	if d.IssueDate.IsZero() {
+		return noteDate
+	}
+	return d.IssueDate
 }
 
 // CreditNote represents the main electronic credit note document.
@@ -21,9 +33,14 @@ type CreditNote struct {
 	Recipient           Recipient           `json:"receptor"`
 	DiscrepancyResponse DiscrepancyResponse `json:"motivo_o_sustento"`
 	Lines               []InvoiceLine       `json:"items"`
+	AllowanceCharges    []AllowanceCharge   `json:"descuentos_cargos,omitempty"` // Global discounts and charges
+	Prepayments         []Prepayment        `json:"anticipos,omitempty"`         // Prepayments of the affected invoice, full annulments only
+	Legends             []Legend            `json:"leyendas,omitempty"`
 	Totals              Totals              `json:"totales"`
-	Status              string              `json:"estado"`              // (aceptado, rechazado, etc.)
-	TicketID            string              `json:"ticket_id,omitempty"` // SUNAT ticket ID for tracking
+	PEN                 *PENEquivalent      `json:"equivalente_pen,omitempty"`  // At the exchange rate of the affected invoice
+	PaymentTerms        *PaymentTerms       `json:"condiciones_pago,omitempty"` // Corrected installments, type 13 only
+	Status              string              `json:"estado"`                     // (aceptado, rechazado, etc.)
+	TicketID            string              `json:"ticket_id,omitempty"`        // SUNAT ticket ID for tracking
 }
 
 // DebitNote represents the main electronic debit note document.
@@ -34,12 +51,14 @@ type DebitNote struct {
 	Series              string              `json:"serie"`
 	Number              int                 `json:"numero"`
 	IssueDate           time.Time           `json:"fecha_emision"`
-	Currency            string              `json:"moneda"` // PEN, USD
+	Currency            string              `json:"moneda"` // Catalog 02, that of the affected invoice
 	Issuer              Issuer              `json:"emisor"`
 	Recipient           Recipient           `json:"receptor"`
 	DiscrepancyResponse DiscrepancyResponse `json:"motivo_o_sustento"`
 	Lines               []InvoiceLine       `json:"items"`
+	Legends             []Legend            `json:"leyendas,omitempty"`
 	Totals              Totals              `json:"totales"`
-	Status              string              `json:"estado"`              // (aceptado, rechazado, etc.)
-	TicketID            string              `json:"ticket_id,omitempty"` // SUNAT ticket ID for tracking
+	PEN                 *PENEquivalent      `json:"equivalente_pen,omitempty"` // At the exchange rate of the affected invoice
+	Status              string              `json:"estado"`                    // (aceptado, rechazado, etc.)
+	TicketID            string              `json:"ticket_id,omitempty"`       // SUNAT ticket ID for tracking
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"time"
+)
+
+// Payment methods accepted by SUNAT in the FormaPago payment terms.
+const (
+	PaymentCash   = "Contado"
+	PaymentCredit = "Credito"
+)
+
+// IGV retention applied by customers designated as retention agents on operations above the
+// threshold, in soles.
+var (
+	IGVRetentionPercent   = decimal.NewFromInt(3)
+	IGVRetentionThreshold = decimal.NewFromInt(700)
+)
+
+// PaymentTerms states whether the invoice is paid in cash or on credit and, for credit,
+// the net amount pending and its installments.
+type PaymentTerms struct {
+	Method        string          `json:"forma_pago"`                    // Contado, Credito
+	PendingAmount decimal.Decimal `json:"monto_neto_pendiente,omitzero"` // Total net of detraction and retention
+	Installments  []Installment   `json:"cuotas,omitempty"`
+}
+
+// Installment is a credit installment (cuota) with its due date.
+type Installment struct {
+	Amount  decimal.Decimal `json:"monto"`
+	DueDate time.Time       `json:"fecha_vencimiento"`
+}
+
+// ApplyPaymentTerms defaults facturas to cash payment and validates credit terms: the
+// pending amount must be the total net of detraction and IGV retention, the installments
+// must add up to it and fall due after the issue date. The detraction, always in soles, and
+// the retention threshold are converted at the exchange rate of invoices in foreign currency.
+func (inv *Invoice) ApplyPaymentTerms() error {
+	if inv.PaymentTerms == nil {
+		if inv.Type != "01" {
+			return nil
+		}
+		inv.PaymentTerms = &PaymentTerms{Method: PaymentCash}
+	}
+
+	pt := inv.PaymentTerms
+	switch pt.Method {
+	case PaymentCash:
+		if len(pt.Installments) > 0 || !pt.PendingAmount.IsZero() {
+			return fmt.Errorf("una venta al contado no puede tener cuotas ni monto pendiente")
+		}
+		return nil
+	case PaymentCredit:
+	default:
+		return fmt.Errorf("forma de pago %q inválida, se espera %s o %s", pt.Method, PaymentCash, PaymentCredit)
+	}
+
+	pending := inv.Totals.Total
+	if inv.Detraction != nil {
+		detraction, ok := inv.amountFromPEN(inv.Detraction.Amount)
+		if !ok {
+			return fmt.Errorf("el monto neto pendiente de comprobantes en %s con detracción requiere el tipo de cambio", inv.Currency)
+		}
+		pending = pending.Sub(detraction)
+	}
+	if inv.Recipient.RetentionAgent {
+		totalPEN, ok := inv.amountPEN(inv.Totals.Total)
+		if !ok {
+			return fmt.Errorf("el monto neto pendiente de comprobantes en %s con retención requiere el tipo de cambio", inv.Currency)
+		}
+		if totalPEN.GreaterThan(IGVRetentionThreshold) {
+			pending = pending.Sub(percentOf(inv.Totals.Total, IGVRetentionPercent))
+		}
+	}
+	pending = Round2(pending)
+	if pt.PendingAmount.IsZero() {
+		pt.PendingAmount = pending
+	}
+	if !pt.PendingAmount.Equal(pending) {
+		return fmt.Errorf("el monto neto pendiente de pago debe ser %s", pending)
+	}
+
+	return pt.validateInstallments(inv.IssueDate)
+}
+
+// validateInstallments checks that the credit installments add up to the pending
+// amount and fall due after the issue date.
+func (pt *PaymentTerms) validateInstallments(issueDate time.Time) error {
+	if len(pt.Installments) == 0 {
+		return fmt.Errorf("una venta al crédito requiere al menos una cuota")
+	}
+	issueDay := calendarDay(issueDate)
+	var sum decimal.Decimal
+	for i, c := range pt.Installments {
+		if !c.Amount.IsPositive() {
+			return fmt.Errorf("el monto de la cuota %d debe ser mayor a cero", i+1)
+		}
+		if !calendarDay(c.DueDate).After(issueDay) {
+			return fmt.Errorf("la fecha de vencimiento de la cuota %d debe ser posterior a la fecha de emisión", i+1)
+		}
+		sum = sum.Add(c.Amount)
+	}
+	if !Round2(sum).Equal(pt.PendingAmount) {
+		return fmt.Errorf("la suma de las cuotas (%s) no coincide con el monto neto pendiente (%s)", sum, pt.PendingAmount)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"time"
+)
+
+// OperationPerception is the catalog 51 operation type of sales subject to perception.
+const OperationPerception = "2001"
+
+// LegendPerception is the catalog 52 code of the perception legend.
+const LegendPerception = "2000"
+
+// perceptionRates holds the catalog 53 perception codes with their percentage.
+var perceptionRates = map[string]decimal.Decimal{
+	"51": decimal.MustParse("2"),   // Percepción venta interna
+	"52": decimal.MustParse("1"),   // Percepción a la adquisición de combustible
+	"53": decimal.MustParse("0.5"), // Percepción realizada al agente de percepción con tasa especial
+}
+
+// Perception is the amount collected by a perception agent on top of the invoice total.
+// Perceptions are always expressed in PEN.
+type Perception struct {
+	Code                string          `json:"codigo_regimen"` // Catalog 53: 51, 52 or 53
+	Percent             decimal.Decimal `json:"porcentaje"`
+	BaseAmount          decimal.Decimal `json:"monto_base"`
+	Amount              decimal.Decimal `json:"monto"`
+	TotalWithPerception decimal.Decimal `json:"total_con_percepcion"`
+}
+
+// ApplyPerception computes the perception when the issuer is a perception agent and the
+// customer is subject to a perception regime, and sets the operation type. The base of
+// invoices in foreign currency is their total in soles.
+func (inv *Invoice) ApplyPerception() error {
+	if inv.Perception == nil {
+		if !inv.Issuer.PerceptionAgent || inv.Recipient.PerceptionRegime == "" || inv.IsExport() {
+			return nil
+		}
+		inv.Perception = &Perception{Code: inv.Recipient.PerceptionRegime}
+	}
+
+	p := inv.Perception
+	rate, ok := perceptionRates[p.Code]
+	if !ok {
+		return fmt.Errorf("régimen de percepción %q no soportado", p.Code)
+	}
+	if !inv.Issuer.PerceptionAgent {
+		return fmt.Errorf("el emisor %s no está designado como agente de percepción", inv.Issuer.RUC)
+	}
+	if inv.Detraction != nil {
+		return fmt.Errorf("una operación sujeta a detracción no puede estar sujeta a percepción")
+	}
+	base, ok := inv.amountPEN(inv.Totals.Total)
+	if !ok {
+		return fmt.Errorf("la percepción de comprobantes en %s requiere el tipo de cambio", inv.Currency)
+	}
+
+	if p.Percent.IsZero() {
+		p.Percent = rate
+	}
+	p.BaseAmount = base
+	p.Amount = Round2(percentOf(p.BaseAmount, p.Percent))
+	p.TotalWithPerception = Round2(p.BaseAmount.Add(p.Amount))
+
+	if inv.OperationType == "" || inv.OperationType == OperationInternalSale {
+		inv.OperationType = OperationPerception
+	}
+	return nil
+}
+
+// PerceptionReportLine summarizes the perception charged on an invoice.
+type PerceptionReportLine struct {
+	InvoiceID           string          `json:"id"`
+	DocType             string          `json:"tipo_comprobante"`
+	DocumentNumber      string          `json:"nro_comprobante"`
+	IssueDate           time.Time       `json:"fecha_emision"`
+	RecipientDocNum     string          `json:"num_doc_cliente"`
+	RecipientName       string          `json:"nombre_cliente"`
+	Currency            string          `json:"moneda"`
+	Total               decimal.Decimal `json:"importe_total"`        // In the currency of the invoice
+	ExchangeRate        decimal.Decimal `json:"tipo_cambio,omitzero"` // Of invoices in foreign currency
+	Code                string          `json:"codigo_regimen"`
+	Percent             decimal.Decimal `json:"porcentaje"`
+	BaseAmount          decimal.Decimal `json:"monto_base"`
+	Amount              decimal.Decimal `json:"monto"`
+	TotalWithPerception decimal.Decimal `json:"total_con_percepcion"`
+	Status              string          `json:"estado"`
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DocTypePerceptionReceipt is the catalog 01 code of the comprobante de percepción.
+const DocTypePerceptionReceipt = "40"
+
+// perceptionReceiptRates holds the catalog 22 perception regimes with their percentage.
+var perceptionReceiptRates = map[string]decimal.Decimal{
+	"01": decimal.MustParse("2"),   // Percepción venta interna
+	"02": decimal.MustParse("1"),   // Percepción a la adquisición de combustible
+	"03": decimal.MustParse("0.5"), // Percepción realizada al agente de percepción con tasa especial
+}
+
+// PerceptionReceipt is the comprobante de percepción a perception agent issues to a customer
+// for the IGV collected on top of the payments of its invoices. Unlike the Perception shown
+// on an invoice, it is a standalone document that may reference several collected invoices.
+type PerceptionReceipt struct {
+	ID             string                `json:"id"`
+	Series         string                `json:"serie"` // P001
+	Number         int                   `json:"numero"`
+	IssueDate      time.Time             `json:"fecha_emision"`
+	Issuer         Issuer                `json:"emisor"` // Perception agent
+	Customer       Recipient             `json:"cliente"`
+	Regime         string                `json:"regimen"` // Catalog 22: 01 (2%), 02 (1%), 03 (0.5%)
+	Percent        decimal.Decimal       `json:"porcentaje"`
+	Observation    string                `json:"observaciones,omitempty"`
+	Documents      []WithholdingDocument `json:"comprobantes"`
+	TotalPerceived decimal.Decimal       `json:"total_percibido"` // PEN
+	TotalCashed    decimal.Decimal       `json:"total_cobrado"`   // Cashed including the perception, in PEN
+	Status         string                `json:"estado"`
+	TicketID       string                `json:"ticket_id,omitempty"`
+	StatusMessage  string                `json:"mensaje_estado,omitempty"`
+}
+
+// Calculate validates the perception receipt and computes the amount perceived on each
+// collection and the totals of the receipt.
+func (p *PerceptionReceipt) Calculate() error {
+	if p.Series == "" || p.Number == 0 {
+		return fmt.Errorf("serie y número son requeridos")
+	}
+	if !strings.HasPrefix(p.Series, "P") {
+		return fmt.Errorf("la serie de un comprobante de percepción debe empezar con P")
+	}
+	if !p.Issuer.PerceptionAgent {
+		return fmt.Errorf("el emisor %s no está designado como agente de percepción", p.Issuer.RUC)
+	}
+	if p.Customer.DocNum == "" || p.Customer.Name == "" {
+		return fmt.Errorf("el documento y nombre del cliente son requeridos")
+	}
+	rate, ok := perceptionReceiptRates[p.Regime]
+	if !ok {
+		return fmt.Errorf("régimen de percepción %q no soportado", p.Regime)
+	}
+	if len(p.Documents) == 0 {
+		return fmt.Errorf("la percepción requiere al menos un comprobante cobrado")
+	}
+
+	p.Percent = rate
+	p.TotalPerceived, p.TotalCashed = decimal.Zero, decimal.Zero
+	for i := range p.Documents {
+		d := &p.Documents[i]
+		if err := d.apply(p.Percent, true); err != nil {
+			return err
+		}
+		p.TotalPerceived = p.TotalPerceived.Add(d.Amount)
+		p.TotalCashed = p.TotalCashed.Add(d.NetAmount)
+	}
+	p.TotalPerceived = Round2(p.TotalPerceived)
+	p.TotalCashed = Round2(p.TotalCashed)
+	return nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document type codes of prepayment invoices (catalog 12).
+const (
+	PrepaymentInvoiceDocType = "02" // Factura emitida por anticipos
+	PrepaymentReceiptDocType = "03" // Boleta de venta emitida por anticipos
+)
+
+// Prepayment is a prepayment invoice deducted from a final invoice.
+type Prepayment struct {
+	ReferenceID   string          `json:"nro_comprobante"`          // Series-number of the prepayment invoice, e.g. F001-10
+	DocType       string          `json:"tipo_documento,omitempty"` // Catalog 12, resolved from the referenced invoice
+	Amount        decimal.Decimal `json:"monto"`                    // Amount deducted, IGV included. Defaults to the remaining balance
+	TaxableAmount decimal.Decimal `json:"monto_base,omitzero"`      // Amount deducted without IGV
+}
+
+// ParseDocumentReference splits a reference such as F001-123 into series and number.
+func ParseDocumentReference(ref string) (string, int, error) {
+	series, number, ok := strings.Cut(ref, "-")
+	if !ok || series == "" {
+		return "", 0, fmt.Errorf("referencia de comprobante %q inválida, se espera SERIE-NUMERO", ref)
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("número de comprobante inválido en la referencia %q", ref)
+	}
+	return series, n, nil
+}
+
+// ApplyPrepayments deducts the prepaid amount (IGV included) from the payable total.
+// The sales price keeps showing the full value of the operation.
+func (t *Totals) ApplyPrepayments(prepaid decimal.Decimal) {
+	t.Prepaid = Round2(prepaid)
+	t.TaxInclusive = Round2(t.TaxInclusive.Add(t.Prepaid))
+	t.Total = Round2(t.TaxInclusive.Sub(t.Allowances).Add(t.Charges).Sub(t.Prepaid))
+}
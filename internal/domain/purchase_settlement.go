@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DocTypePurchaseSettlement is the catalog 01 code of the liquidación de compra.
+const DocTypePurchaseSettlement = "04"
+
+// OperationPurchase is the catalog 51 operation type of a domestic purchase.
+const OperationPurchase = "0501"
+
+// TaxSchemeIncomeTax is the catalog 05 code of the income tax (renta) retained on a purchase settlement.
+const TaxSchemeIncomeTax = "3000"
+
+// IncomeTaxRetentionPercent is the income tax the buyer retains from the seller of a purchase settlement.
+var IncomeTaxRetentionPercent = decimal.MustParse("1.5")
+
+// PurchaseSettlement is the liquidación de compra a company issues when it buys from a
+// seller without RUC, e.g. a farmer. The roles are inverted with respect to an invoice: the
+// issuer is the buyer and retains the IGV and the income tax of the seller.
+type PurchaseSettlement struct {
+	ID                string          `json:"id"`
+	Series            string          `json:"serie"` // E001
+	Number            int             `json:"numero"`
+	IssueDate         time.Time       `json:"fecha_emision"`
+	Currency          string          `json:"moneda"`   // Catalog 02
+	Issuer            Issuer          `json:"emisor"`   // Buyer
+	Seller            Recipient       `json:"vendedor"` // Identified by DNI or another document, never RUC
+	OperationPlace    Location        `json:"lugar_operacion"`
+	Lines             []InvoiceLine   `json:"items"`
+	Legends           []Legend        `json:"leyendas,omitempty"`
+	Totals            Totals          `json:"totales"`
+	IGVRetained       decimal.Decimal `json:"igv_retenido"`
+	IncomeTaxRetained decimal.Decimal `json:"renta_retenida"`
+	NetPayable        decimal.Decimal `json:"neto_pagar"` // Paid to the seller after the retentions
+	PEN               *PENEquivalent  `json:"equivalente_pen,omitempty"`
+	Status            string          `json:"estado"`
+	TicketID          string          `json:"ticket_id,omitempty"`
+}
+
+// Validate checks the parties of the settlement: an E series, a seller without RUC and the
+// place where the operation took place.
+func (ps *PurchaseSettlement) Validate() error {
+	if ps.Series == "" || ps.Number == 0 {
+		return fmt.Errorf("serie y número son requeridos")
+	}
+	if !strings.HasPrefix(ps.Series, "E") {
+		return fmt.Errorf("la serie de una liquidación de compra debe empezar con E")
+	}
+	if err := ValidateCurrency(ps.Currency); err != nil {
+		return err
+	}
+	if ps.Seller.DocNum == "" || ps.Seller.Name == "" {
+		return fmt.Errorf("el documento y nombre del vendedor son requeridos")
+	}
+	if IdentityDocCode(ps.Seller.DocType) == "6" {
+		return fmt.Errorf("el vendedor con RUC debe emitir su propio comprobante, no corresponde una liquidación de compra")
+	}
+	if len(ps.Lines) == 0 {
+		return fmt.Errorf("la liquidación de compra requiere al menos un ítem")
+	}
+	for _, line := range ps.Lines {
+		if line.IsFree() || line.TaxScheme() == TaxSchemeExport {
+			return fmt.Errorf("el ítem %s tiene una afectación %s no permitida en una liquidación de compra", line.Description, line.Affectation())
+		}
+	}
+	return ps.OperationPlace.validate("la operación")
+}
+
+// ApplyRetentions computes the IGV and income tax the buyer retains and the net amount
+// paid to the seller. The totals must be calculated first.
+func (ps *PurchaseSettlement) ApplyRetentions() {
+	ps.IGVRetained = ps.Totals.IGV
+	ps.IncomeTaxRetained = Round2(percentOf(ps.Totals.Gross, IncomeTaxRetentionPercent))
+	ps.NetPayable = Round2(ps.Totals.Total.Sub(ps.IGVRetained).Sub(ps.IncomeTaxRetained))
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "context"
+import (
+	"FacturacionSunat/pkg/decimal"
+	"context"
+	"time"
+)
 
 // InvoiceRepository defines the persistence interface for Invoices.
 type InvoiceRepository interface {
@@ -10,6 +14,171 @@ type InvoiceRepository interface {
 	// FindByID retrieves an invoice by its ID.
 	FindByID(ctx context.Context, id string) (*Invoice, error)
 
+	// FindByNumber retrieves an invoice of the issuer by its series and number.
+	FindByNumber(ctx context.Context, ruc, series string, number int) (*Invoice, error)
+
+	// FindByIssueDate retrieves the invoices of the issuer issued between from and to, both inclusive.
+	FindByIssueDate(ctx context.Context, ruc string, from, to time.Time) ([]*Invoice, error)
+
 	// UpdateStatus updates the status of a given invoice.
 	UpdateStatus(ctx context.Context, id string, status string) error
+
+	// UpdatePrepaidBalance updates the remaining balance of a prepayment invoice.
+	UpdatePrepaidBalance(ctx context.Context, id string, balance decimal.Decimal) error
+}
+
+// NoteRepository defines the persistence interface for credit and debit notes.
+type NoteRepository interface {
+	// SaveCreditNote saves a given credit note to the repository.
+	SaveCreditNote(ctx context.Context, cn *CreditNote) error
+
+	// UpdateCreditNoteStatus updates the status of a credit note and the ticket returned by SUNAT.
+	UpdateCreditNoteStatus(ctx context.Context, id, status, ticketID string) error
+
+	// SaveDebitNote saves a given debit note to the repository.
+	SaveDebitNote(ctx context.Context, dn *DebitNote) error
+
+	// UpdateDebitNoteStatus updates the status of a debit note and the ticket returned by SUNAT.
+	UpdateDebitNoteStatus(ctx context.Context, id, status, ticketID string) error
+
+	// FindCreditNotesByReference retrieves the credit notes of the issuer that reference the given document.
+	FindCreditNotesByReference(ctx context.Context, ruc, series string, number int) ([]*CreditNote, error)
+
+	// FindDebitNotesByReference retrieves the debit notes of the issuer that reference the given document.
+	FindDebitNotesByReference(ctx context.Context, ruc, series string, number int) ([]*DebitNote, error)
+}
+
+// SummaryRepository defines the persistence interface for daily summaries and the
+// documents waiting to be reported in them.
+type SummaryRepository interface {
+	// AddPendingLine queues a document of the issuer to be reported in a daily summary.
+	AddPendingLine(ctx context.Context, ruc string, line SummaryLine) error
+
+	// FindPendingLines retrieves the queued documents of the issuer issued on the given day.
+	FindPendingLines(ctx context.Context, ruc string, day time.Time) ([]SummaryLine, error)
+
+	// Save saves a summary and removes its lines from the pending queue.
+	Save(ctx context.Context, summary *DailySummary) error
+
+	// CountByIssueDate returns how many summaries the issuer generated on the given day.
+	CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error)
+}
+
+// DebitNoteDraftRepository defines the persistence interface for debit note drafts.
+type DebitNoteDraftRepository interface {
+	// Save saves a given draft to the repository.
+	Save(ctx context.Context, draft *DebitNoteDraft) error
+
+	// FindByID retrieves a draft by its ID.
+	FindByID(ctx context.Context, id string) (*DebitNoteDraft, error)
+
+	// FindByInvoice retrieves the drafts generated for an invoice.
+	FindByInvoice(ctx context.Context, invoiceID string) ([]*DebitNoteDraft, error)
+
+	// FindByStatus retrieves the drafts of the issuer with the given status.
+	FindByStatus(ctx context.Context, ruc, status string) ([]*DebitNoteDraft, error)
+
+	// UpdateStatus updates the status of a draft and the ID of the debit note issued from it.
+	UpdateStatus(ctx context.Context, id, status, debitNoteID string) error
+}
+
+// DespatchAdviceRepository defines the persistence interface for remission guides.
+type DespatchAdviceRepository interface {
+	// Save saves a given remission guide to the repository.
+	Save(ctx context.Context, guide *DespatchAdvice) error
+
+	// FindByID retrieves a remission guide by its ID.
+	FindByID(ctx context.Context, id string) (*DespatchAdvice, error)
+
+	// UpdateStatus updates the status of a remission guide and the ticket or error returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID, statusError string) error
+}
+
+// RetentionRepository defines the persistence interface for retention certificates.
+type RetentionRepository interface {
+	// Save saves a given retention certificate to the repository.
+	Save(ctx context.Context, retention *Retention) error
+
+	// FindByID retrieves a retention certificate by its ID.
+	FindByID(ctx context.Context, id string) (*Retention, error)
+
+	// UpdateStatus updates the status of a retention certificate and the ticket returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID string) error
+}
+
+// PerceptionReceiptRepository defines the persistence interface for perception receipts.
+type PerceptionReceiptRepository interface {
+	// Save saves a given perception receipt to the repository.
+	Save(ctx context.Context, receipt *PerceptionReceipt) error
+
+	// FindByID retrieves a perception receipt by its ID.
+	FindByID(ctx context.Context, id string) (*PerceptionReceipt, error)
+
+	// FindByIssuer retrieves the perception receipts of an issuer issued between from and to, inclusive.
+	FindByIssuer(ctx context.Context, ruc string, from, to time.Time) ([]*PerceptionReceipt, error)
+
+	// UpdateStatus updates the status of a perception receipt and the ticket or message returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error
+}
+
+// ReversalSummaryRepository defines the persistence interface for reversal summaries.
+type ReversalSummaryRepository interface {
+	// Save saves a given reversal summary to the repository.
+	Save(ctx context.Context, summary *ReversalSummary) error
+
+	// FindByID retrieves a reversal summary by its ID.
+	FindByID(ctx context.Context, id string) (*ReversalSummary, error)
+
+	// UpdateStatus updates the status of a reversal summary and the ticket or message returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error
+
+	// CountByIssueDate returns how many reversal summaries the issuer generated on the given day.
+	CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error)
+}
+
+// PurchaseSettlementRepository defines the persistence interface for purchase settlements.
+type PurchaseSettlementRepository interface {
+	// Save saves a given purchase settlement to the repository.
+	Save(ctx context.Context, settlement *PurchaseSettlement) error
+
+	// FindByID retrieves a purchase settlement by its ID.
+	FindByID(ctx context.Context, id string) (*PurchaseSettlement, error)
+
+	// UpdateStatus updates the status of a purchase settlement and the ticket returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID string) error
+}
+
+// ContingencyRepository defines the persistence interface for the documents issued in
+// contingency and the summaries (RF) that report them.
+type ContingencyRepository interface {
+	// NextNumber reserves the next number of a contingency series of the issuer.
+	NextNumber(ctx context.Context, ruc, docType, series string) (int, error)
+
+	// AddPendingLine queues a document of the issuer issued in contingency.
+	AddPendingLine(ctx context.Context, ruc string, line ContingencyLine) error
+
+	// FindPendingLines retrieves the queued documents of the issuer, oldest first.
+	FindPendingLines(ctx context.Context, ruc string) ([]ContingencyLine, error)
+
+	// Save saves a summary and removes its lines from the pending queue.
+	Save(ctx context.Context, summary *ContingencySummary) error
+
+	// FindByID retrieves a contingency summary by its ID.
+	FindByID(ctx context.Context, id string) (*ContingencySummary, error)
+
+	// UpdateStatus updates the status of a contingency summary and the ticket or message returned by SUNAT.
+	UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error
+
+	// CountByIssueDate returns how many contingency summaries the issuer generated on the given day.
+	CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error)
+}
+
+// ExchangeRateRepository defines the persistence interface for the daily exchange rates.
+type ExchangeRateRepository interface {
+	// SaveAll saves the given rates, replacing those stored for the same currency and day.
+	SaveAll(ctx context.Context, rates []ExchangeRate) error
+
+	// FindLatest retrieves the rate of the currency published on the given day or, when there
+	// is none, the last one published before it.
+	FindLatest(ctx context.Context, currency string, day time.Time) (*ExchangeRate, error)
 }
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DocTypeRetention is the catalog 01 code of the comprobante de retención.
+const DocTypeRetention = "20"
+
+// retentionRates holds the catalog 23 retention regimes with their percentage.
+var retentionRates = map[string]decimal.Decimal{
+	"01": decimal.MustParse("3"), // Tasa 3%
+	"02": decimal.MustParse("6"), // Tasa 6%
+}
+
+// Retention is the comprobante de retención a withholding agent issues to a supplier for
+// the IGV retained from the payments of its invoices.
+type Retention struct {
+	ID            string                `json:"id"`
+	Series        string                `json:"serie"` // R001
+	Number        int                   `json:"numero"`
+	IssueDate     time.Time             `json:"fecha_emision"`
+	Issuer        Issuer                `json:"emisor"`    // Retention agent
+	Supplier      Recipient             `json:"proveedor"` // Identified by RUC
+	Regime        string                `json:"regimen"`   // Catalog 23: 01 (3%), 02 (6%)
+	Percent       decimal.Decimal       `json:"porcentaje"`
+	Observation   string                `json:"observaciones,omitempty"`
+	Documents     []WithholdingDocument `json:"comprobantes"`
+	TotalRetained decimal.Decimal       `json:"total_retenido"` // PEN
+	TotalPaid     decimal.Decimal       `json:"total_pagado"`   // Net paid in PEN
+	Status        string                `json:"estado"`
+	TicketID      string                `json:"ticket_id,omitempty"`
+}
+
+// Calculate validates the retention and computes the amount retained from each payment and
+// the totals of the certificate.
+func (r *Retention) Calculate() error {
+	if r.Series == "" || r.Number == 0 {
+		return fmt.Errorf("serie y número son requeridos")
+	}
+	if !strings.HasPrefix(r.Series, "R") {
+		return fmt.Errorf("la serie de un comprobante de retención debe empezar con R")
+	}
+	if !r.Issuer.RetentionAgent {
+		return fmt.Errorf("el emisor %s no está designado como agente de retención", r.Issuer.RUC)
+	}
+	if IdentityDocCode(r.Supplier.DocType) != "6" {
+		return fmt.Errorf("el proveedor de una retención debe identificarse con RUC")
+	}
+	rate, ok := retentionRates[r.Regime]
+	if !ok {
+		return fmt.Errorf("régimen de retención %q no soportado", r.Regime)
+	}
+	if len(r.Documents) == 0 {
+		return fmt.Errorf("la retención requiere al menos un comprobante pagado")
+	}
+
+	r.Percent = rate
+	r.TotalRetained, r.TotalPaid = decimal.Zero, decimal.Zero
+	for i := range r.Documents {
+		d := &r.Documents[i]
+		if err := d.apply(r.Percent, false); err != nil {
+			return err
+		}
+		r.TotalRetained = r.TotalRetained.Add(d.Amount)
+		r.TotalPaid = r.TotalPaid.Add(d.NetAmount)
+	}
+	r.TotalRetained = Round2(r.TotalRetained)
+	r.TotalPaid = Round2(r.TotalPaid)
+	return nil
+}
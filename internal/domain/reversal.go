@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatusReverted is the status of a retention or perception certificate voided by an
+// accepted reversal summary.
+const StatusReverted = "REVERTIDO"
+
+// ReversalSummary is the resumen de reversiones (RR) that voids retention and perception
+// certificates. Those certificates cannot be voided with a comunicación de baja.
+type ReversalSummary struct {
+	ID            string         `json:"id"` // RR-YYYYMMDD-N
+	Issuer        Issuer         `json:"emisor"`
+	ReferenceDate time.Time      `json:"fecha_emision_comprobantes"` // Issue date of the reverted certificates
+	IssueDate     time.Time      `json:"fecha_generacion"`
+	Lines         []ReversalLine `json:"items"`
+	Status        string         `json:"estado"`
+	TicketID      string         `json:"ticket_id,omitempty"`
+	StatusMessage string         `json:"mensaje_estado,omitempty"`
+}
+
+// ReversalLine is a certificate reverted by a reversal summary.
+type ReversalLine struct {
+	DocumentID string `json:"id_comprobante"`
+	DocType    string `json:"tipo_comprobante"` // 20: Retención, 40: Percepción
+	Series     string `json:"serie"`
+	Number     int    `json:"numero"`
+	Reason     string `json:"motivo"`
+}
+
+// CheckRevertible reports whether a certificate in the given status can be reverted: it must
+// have been sent to SUNAT and not be reverted already.
+func CheckRevertible(series string, number int, status string) error {
+	switch status {
+	case "ENVIADO", "ACEPTADO":
+		return nil
+	case StatusReverted:
+		return fmt.Errorf("el comprobante %s-%d ya fue revertido", series, number)
+	default:
+		return fmt.Errorf("el comprobante %s-%d en estado %s no puede revertirse", series, number, status)
+	}
+}
+
+// Validate checks the reversal summary before it is sent.
+func (r *ReversalSummary) Validate() error {
+	if len(r.Lines) == 0 {
+		return fmt.Errorf("el resumen de reversiones no tiene comprobantes")
+	}
+	for _, line := range r.Lines {
+		if line.DocType != DocTypeRetention && line.DocType != DocTypePerceptionReceipt {
+			return fmt.Errorf("el resumen de reversiones solo admite retenciones y percepciones, no el tipo %s", line.DocType)
+		}
+		if line.Reason == "" {
+			return fmt.Errorf("el motivo de reversión del comprobante %s-%d es requerido", line.Series, line.Number)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Transport modes (catalog 18).
+const (
+	TransportPublic  = "01" // Transporte público
+	TransportPrivate = "02" // Transporte privado
+)
+
+// transferReasons holds the catalog 20 reasons of a transfer of goods.
+var transferReasons = map[string]string{
+	"01": "Venta",
+	"02": "Compra",
+	"03": "Venta con entrega a terceros",
+	"04": "Traslado entre establecimientos de la misma empresa",
+	"05": "Consignación",
+	"06": "Devolución",
+	"07": "Recojo de bienes transformados",
+	"08": "Importación",
+	"09": "Exportación",
+	"13": "Otros",
+	"14": "Venta sujeta a confirmación del comprador",
+	"17": "Traslado de bienes para transformación",
+	"18": "Traslado emisor itinerante CP",
+	"19": "Traslado a zona primaria",
+}
+
+// saleTransferReasons holds the catalog 20 reasons a factura guía can support.
+var saleTransferReasons = map[string]bool{"01": true, "09": true, "14": true}
+
+var (
+	ubigeoPattern = regexp.MustCompile(`^\d{6}$`)
+	rucPattern    = regexp.MustCompile(`^\d{11}$`)
+	platePattern  = regexp.MustCompile(`^[A-Z0-9]{6,8}$`)
+)
+
+// Shipment holds the transport data of a factura guía remitente, an invoice that also
+// serves as the remission guide of the goods sold.
+type Shipment struct {
+	TransferReason string          `json:"motivo_traslado"`      // Catalog 20
+	TransportMode  string          `json:"modalidad_transporte"` // Catalog 18: 01 público, 02 privado
+	GrossWeight    decimal.Decimal `json:"peso_bruto"`
+	WeightUnit     string          `json:"unidad_peso,omitempty"` // KGM by default
+	StartDate      time.Time       `json:"fecha_inicio_traslado"`
+	Carrier        *Carrier        `json:"transportista,omitempty"` // Public transport
+	VehiclePlate   string          `json:"placa_vehiculo,omitempty"`
+	Driver         *Driver         `json:"conductor,omitempty"` // Private transport
+	Origin         Location        `json:"punto_partida"`
+	Destination    Location        `json:"punto_llegada"`
+}
+
+// Carrier is the transport company hired for a public transport.
+type Carrier struct {
+	RUC             string `json:"ruc"`
+	Name            string `json:"razon_social"`
+	MTCRegistration string `json:"registro_mtc,omitempty"`
+}
+
+// Driver is the driver of the vehicle of a private transport.
+type Driver struct {
+	DocType   string `json:"tipo_documento"`
+	DocNum    string `json:"numero_documento"`
+	FirstName string `json:"nombres"`
+	LastName  string `json:"apellidos"`
+	License   string `json:"licencia"`
+}
+
+// Location is a point of departure or arrival of a transfer.
+type Location struct {
+	Ubigeo  string `json:"ubigeo"` // INEI district code
+	Address string `json:"direccion"`
+}
+
+// Weight returns the unit of the gross weight, kilograms by default.
+func (s *Shipment) Weight() string {
+	if s.WeightUnit == "" {
+		return "KGM"
+	}
+	return s.WeightUnit
+}
+
+// ValidateShipment checks the transport data of a factura guía: only facturas may carry it,
+// the transfer must be a sale, and the carrier (public transport) or the vehicle and driver
+// (private transport) are required together with the departure and arrival points.
+func (inv *Invoice) ValidateShipment() error {
+	s := inv.Shipment
+	if s == nil {
+		return nil
+	}
+	if inv.Type != "01" {
+		return fmt.Errorf("solo una factura puede emitirse como factura guía")
+	}
+	if !saleTransferReasons[s.TransferReason] {
+		return fmt.Errorf("motivo de traslado %q no permitido en una factura guía", s.TransferReason)
+	}
+	if err := s.validateLoad(inv.IssueDate); err != nil {
+		return err
+	}
+	return s.validateMode()
+}
+
+// TransferReasonDescription returns the catalog 20 description of the transfer reason.
+func (s *Shipment) TransferReasonDescription() string {
+	return transferReasons[s.TransferReason]
+}
+
+// validateLoad checks the weight, the start date and the departure and arrival points.
+func (s *Shipment) validateLoad(issueDate time.Time) error {
+	if !s.GrossWeight.IsPositive() {
+		return fmt.Errorf("el peso bruto del traslado debe ser mayor a cero")
+	}
+	if s.StartDate.IsZero() {
+		s.StartDate = issueDate
+	}
+	if calendarDay(s.StartDate).Before(calendarDay(issueDate)) {
+		return fmt.Errorf("la fecha de inicio del traslado no puede ser anterior a la fecha de emisión")
+	}
+	if err := s.Origin.validate("partida"); err != nil {
+		return err
+	}
+	return s.Destination.validate("llegada")
+}
+
+// validateMode checks the carrier of a public transport or the vehicle and driver of a
+// private one.
+func (s *Shipment) validateMode() error {
+	switch s.TransportMode {
+	case TransportPublic:
+		if s.Carrier == nil || !rucPattern.MatchString(s.Carrier.RUC) || s.Carrier.Name == "" {
+			return fmt.Errorf("el transporte público requiere el RUC y la razón social del transportista")
+		}
+		return nil
+	case TransportPrivate:
+		return s.validateVehicle()
+	default:
+		return fmt.Errorf("modalidad de transporte %q no soportada", s.TransportMode)
+	}
+}
+
+// validateVehicle checks the plate of the vehicle and the document and license of the driver.
+func (s *Shipment) validateVehicle() error {
+	if !platePattern.MatchString(s.VehiclePlate) {
+		return fmt.Errorf("placa de vehículo %q inválida", s.VehiclePlate)
+	}
+	if d := s.Driver; d == nil || d.DocNum == "" || d.License == "" {
+		return fmt.Errorf("el transporte requiere el documento y la licencia del conductor")
+	}
+	return nil
+}
+
+// validate checks that the location has a valid ubigeo and an address.
+func (l Location) validate(name string) error {
+	if !ubigeoPattern.MatchString(l.Ubigeo) || l.Address == "" {
+		return fmt.Errorf("el punto de %s requiere un ubigeo de 6 dígitos y la dirección", name)
+	}
+	return nil
+}
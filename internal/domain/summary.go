@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Conditions of a document reported in a daily summary.
+const (
+	SummaryConditionAdd    = "1" // Adicionar
+	SummaryConditionModify = "2" // Modificar
+	SummaryConditionVoid   = "3" // Anulado
+)
+
+// DailySummary is the resumen diario (RC) that reports boletas and the notes that
+// reference them, instead of sending each one with sendBill.
+type DailySummary struct {
+	ID            string        `json:"id"` // RC-YYYYMMDD-N
+	Issuer        Issuer        `json:"emisor"`
+	ReferenceDate time.Time     `json:"fecha_emision_comprobantes"` // Issue date of the summarized documents
+	IssueDate     time.Time     `json:"fecha_generacion"`
+	Lines         []SummaryLine `json:"items"`
+	Status        string        `json:"estado"`
+	TicketID      string        `json:"ticket_id,omitempty"`
+}
+
+// SummaryLine is a document reported in a daily summary.
+type SummaryLine struct {
+	DocType          string    `json:"tipo_comprobante"` // 03: Boleta, 07: Credit Note, 08: Debit Note
+	Series           string    `json:"serie"`
+	Number           int       `json:"numero"`
+	IssueDate        time.Time `json:"fecha_emision"`
+	Currency         string    `json:"moneda"`
+	Recipient        Recipient `json:"receptor"`
+	ReferenceID      string    `json:"nro_comprobante_afectado,omitempty"` // Notes only
+	ReferenceDocType string    `json:"tipo_comprobante_afectado,omitempty"`
+	ReferenceDate    time.Time `json:"fecha_emision_afectado,omitzero"`
+	Condition        string    `json:"condicion"` // 1: Adicionar, 2: Modificar, 3: Anulado
+	Totals           Totals    `json:"totales"`
+}
+
+// TaxDate returns the date whose tax rates apply to the line: that of the affected boleta for
+// notes.
+func (l SummaryLine) TaxDate() time.Time {
+	if l.ReferenceDate.IsZero() {
+		return l.IssueDate
+	}
+	return l.ReferenceDate
+}
+
+// SummaryLineFromCreditNote builds the summary line of a credit note that references a boleta.
+func SummaryLineFromCreditNote(cn *CreditNote) SummaryLine {
+	return SummaryLine{
+		DocType:          cn.Type,
+		Series:           cn.Series,
+		Number:           cn.Number,
+		IssueDate:        cn.IssueDate,
+		ReferenceDate:    cn.DiscrepancyResponse.IssueDate,
+		Currency:         cn.Currency,
+		Recipient:        cn.Recipient,
+		ReferenceID:      cn.DiscrepancyResponse.ReferenceID,
+		ReferenceDocType: cn.DiscrepancyResponse.DocType,
+		Condition:        SummaryConditionAdd,
+		Totals:           cn.Totals,
+	}
+}
+
+// SummaryLineFromDebitNote builds the summary line of a debit note that references a boleta.
+func SummaryLineFromDebitNote(dn *DebitNote) SummaryLine {
+	return SummaryLine{
+		DocType:          dn.Type,
+		Series:           dn.Series,
+		Number:           dn.Number,
+		IssueDate:        dn.IssueDate,
+		ReferenceDate:    dn.DiscrepancyResponse.IssueDate,
+		Currency:         dn.Currency,
+		Recipient:        dn.Recipient,
+		ReferenceID:      dn.DiscrepancyResponse.ReferenceID,
+		ReferenceDocType: dn.DiscrepancyResponse.DocType,
+		Condition:        SummaryConditionAdd,
+		Totals:           dn.Totals,
+	}
+}
+
+// CheckNoteSeries enforces that a note belongs to the same series family as the document it
+// references: notes of facturas use series starting with F and notes of boletas with B.
+func CheckNoteSeries(series string, original *Invoice) error {
+	if original.Series == "" {
+		return nil
+	}
+	family := original.Series[:1]
+	if family != "F" && family != "B" {
+		return nil // Contingency series are numeric
+	}
+	if !strings.HasPrefix(series, family) {
+		return fmt.Errorf("la serie %s no corresponde al comprobante %s-%d, debe empezar con %s", series, original.Series, original.Number, family)
+	}
+	return nil
+}
+
+// ReportsInSummary reports whether a document of the given type, or a note referencing it,
+// is informed through the daily summary instead of sendBill.
+func ReportsInSummary(docType string) bool {
+	return docType == "03"
+}
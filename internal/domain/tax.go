@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tax scheme codes (SUNAT catalog 05).
+const (
+	TaxSchemeIGV  = "1000" // IGV + IPM
+	TaxSchemeIVAP = "1016" // Impuesto a la Venta de Arroz Pilado
+)
+
+// Issuer regimes that change the applicable tax rates.
+const (
+	RegimeGeneral = "GENERAL"
+	// RegimeMYPERestaurant covers micro and small companies whose main activity is
+	// restaurants, hotels or tourist lodging (Ley 31556).
+	RegimeMYPERestaurant = "MYPE_RESTAURANTE"
+)
+
+// TaxRate is the percentage of a tax scheme in force during a period.
+type TaxRate struct {
+	Scheme    string    // Catalog 05 code, e.g. 1000 for IGV
+	Regime    string    // Empty means it applies to every regime
+	ValidFrom time.Time // First day the rate applies
+	ValidTo   time.Time // Last day the rate applies, zero if still in force
+	Percent   decimal.Decimal
+}
+
+// appliesOn reports whether the rate is in force on the given day.
+func (r TaxRate) appliesOn(day time.Time) bool {
+	if day.Before(r.ValidFrom) {
+		return false
+	}
+	return r.ValidTo.IsZero() || !day.After(r.ValidTo)
+}
+
+// TaxRateRegistry resolves the tax percentage for a scheme, issuer regime and issue date.
+type TaxRateRegistry struct {
+	mu    sync.RWMutex
+	rates []TaxRate
+}
+
+// NewTaxRateRegistry creates a registry with the given rates.
+func NewTaxRateRegistry(rates ...TaxRate) *TaxRateRegistry {
+	return &TaxRateRegistry{rates: rates}
+}
+
+// Register adds a rate to the registry, e.g. an extension of a temporary regime.
+func (r *TaxRateRegistry) Register(rate TaxRate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates = append(r.rates, rate)
+}
+
+// Lookup returns the percentage of the scheme in force on the given date.
+// A rate registered for the issuer regime takes precedence over the general one.
+func (r *TaxRateRegistry) Lookup(scheme, regime string, date time.Time) (decimal.Decimal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	day := calendarDay(date)
+	var general *TaxRate
+	for i := range r.rates {
+		rate := &r.rates[i]
+		if rate.Scheme != scheme || !rate.appliesOn(day) {
+			continue
+		}
+		if regime != "" && rate.Regime == regime {
+			return rate.Percent, nil
+		}
+		if rate.Regime == "" && general == nil {
+			general = rate
+		}
+	}
+	if general == nil {
+		return decimal.Zero, fmt.Errorf("no existe tasa para el tributo %s en la fecha %s", scheme, day.Format("2006-01-02"))
+	}
+	return general.Percent, nil
+}
+
+// DefaultTaxRates holds the historical rates known to the system.
+var DefaultTaxRates = NewTaxRateRegistry(
+	TaxRate{Scheme: TaxSchemeIGV, ValidFrom: newDate(2003, 8, 1), ValidTo: newDate(2011, 2, 28), Percent: decimal.NewFromInt(19)},
+	TaxRate{Scheme: TaxSchemeIGV, ValidFrom: newDate(2011, 3, 1), Percent: decimal.NewFromInt(18)},
+	// Ley 31556: 8% IGV + 2% IPM, extended by Ley 32219 with a gradual return to the
+	// general rate from 2028. Later extensions are added with Register.
+	TaxRate{Scheme: TaxSchemeIGV, Regime: RegimeMYPERestaurant, ValidFrom: newDate(2022, 9, 1), ValidTo: newDate(2026, 12, 31), Percent: decimal.NewFromInt(10)},
+	TaxRate{Scheme: TaxSchemeIGV, Regime: RegimeMYPERestaurant, ValidFrom: newDate(2027, 1, 1), ValidTo: newDate(2027, 12, 31), Percent: decimal.NewFromInt(14)},
+	TaxRate{Scheme: TaxSchemeIVAP, ValidFrom: newDate(2004, 4, 1), Percent: decimal.NewFromInt(4)},
+)
+
+func newDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// calendarDay drops the time of day so that rates are compared by date only.
+func calendarDay(t time.Time) time.Time {
+	return newDate(t.Year(), t.Month(), t.Day())
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+)
+
+// CalculateTotals computes the value and tax of every line with the percentages of its
+// catalog 07 affectation, applies the line and document allowances and charges, and returns
+// the document totals broken down by tax scheme.
+func CalculateTotals(lines []InvoiceLine, allowanceCharges []AllowanceCharge, percents TaxPercents) (Totals, error) {
+	var totals Totals
+	for i := range lines {
+		line := &lines[i]
+		if err := line.validateProperties(); err != nil {
+			return Totals{}, fmt.Errorf("ítem %d: %w", i+1, err)
+		}
+		value := Round2(line.Quantity.Mul(line.UnitPrice))
+		if value.IsZero() {
+			value = line.TotalValue
+		}
+
+		lineValue := value
+		for j := range line.AllowanceCharges {
+			ac := &line.AllowanceCharges[j]
+			reason, err := ac.resolve(value, true)
+			if err != nil {
+				return Totals{}, fmt.Errorf("ítem %d: %w", i+1, err)
+			}
+			totals.addAllowanceCharge(&lineValue, reason, ac.Amount)
+		}
+		percent, err := line.taxPercent(percents)
+		if err != nil {
+			return Totals{}, fmt.Errorf("ítem %d: %w", i+1, err)
+		}
+		line.TotalValue = Round2(lineValue)
+		line.IGV = Round2(percentOf(line.TotalValue, percent))
+
+		// Free transfers are reported at their referential value but not charged.
+		if line.IsFree() {
+			totals.Free = totals.Free.Add(line.TotalValue)
+			totals.FreeTax = totals.FreeTax.Add(line.IGV)
+			continue
+		}
+		base := totals.base(line.TaxScheme())
+		*base = base.Add(line.TotalValue)
+		totals.Gross = totals.Gross.Add(line.TotalValue)
+	}
+	totals.Gross = Round2(totals.Gross)
+
+	// Global allowances and charges that affect the base adjust the first base with value.
+	base := totals.mainBase()
+	for j := range allowanceCharges {
+		ac := &allowanceCharges[j]
+		reason, err := ac.resolve(totals.Gross, false)
+		if err != nil {
+			return Totals{}, err
+		}
+		totals.addAllowanceCharge(base, reason, ac.Amount)
+	}
+
+	totals.Taxable = Round2(totals.Taxable)
+	totals.IVAPBase = Round2(totals.IVAPBase)
+	totals.Exonerated = Round2(totals.Exonerated)
+	totals.Unaffected = Round2(totals.Unaffected)
+	totals.Exported = Round2(totals.Exported)
+	totals.Free = Round2(totals.Free)
+	totals.FreeTax = Round2(totals.FreeTax)
+	totals.IGV = Round2(percentOf(totals.Taxable, percents[TaxSchemeIGV]))
+	totals.IVAP = Round2(percentOf(totals.IVAPBase, percents[TaxSchemeIVAP]))
+	totals.TaxInclusive = Round2(totals.Taxable.Add(totals.IVAPBase).Add(totals.Exonerated).Add(totals.Unaffected).Add(totals.Exported).Add(totals.IGV).Add(totals.IVAP))
+	totals.Allowances = Round2(totals.Allowances)
+	totals.Charges = Round2(totals.Charges)
+	totals.Total = Round2(totals.TaxInclusive.Sub(totals.Allowances).Add(totals.Charges))
+	return totals, nil
+}
+
+// base returns the total that accumulates the values of the given tax scheme.
+func (t *Totals) base(scheme string) *decimal.Decimal {
+	switch scheme {
+	case TaxSchemeIVAP:
+		return &t.IVAPBase
+	case TaxSchemeExonerated:
+		return &t.Exonerated
+	case TaxSchemeUnaffected:
+		return &t.Unaffected
+	case TaxSchemeExport:
+		return &t.Exported
+	default:
+		return &t.Taxable
+	}
+}
+
+// mainBase returns the first base with value, the IGV base when the document has none.
+func (t *Totals) mainBase() *decimal.Decimal {
+	for _, scheme := range []string{TaxSchemeIGV, TaxSchemeIVAP, TaxSchemeExonerated, TaxSchemeUnaffected, TaxSchemeExport} {
+		if b := t.base(scheme); !b.IsZero() {
+			return b
+		}
+	}
+	return &t.Taxable
+}
+
+// addAllowanceCharge applies an amount to the taxable base when the reason affects it,
+// otherwise accumulates it in the allowance or charge totals.
+func (t *Totals) addAllowanceCharge(base *decimal.Decimal, reason allowanceChargeReason, amount decimal.Decimal) {
+	switch {
+	case reason.affectsBase && reason.charge:
+		*base = base.Add(amount)
+	case reason.affectsBase:
+		*base = base.Sub(amount)
+	case reason.charge:
+		t.Charges = t.Charges.Add(amount)
+	default:
+		t.Allowances = t.Allowances.Add(amount)
+	}
+}
+
+// hundred divides percentages.
+var hundred = decimal.NewFromInt(100)
+
+// Round2 rounds an amount half away from zero to two decimals, the precision SUNAT expects for amounts.
+func Round2(v decimal.Decimal) decimal.Decimal {
+	return v.Round(2)
+}
+
+// percentOf returns the given percentage of an amount, unrounded.
+func percentOf(amount, percent decimal.Decimal) decimal.Decimal {
+	return amount.Mul(percent).Div(hundred)
+}
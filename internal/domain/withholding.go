@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"time"
+)
+
+// WithholdingDocument is a document paid or collected that a retention or perception
+// certificate references. The retained or perceived amounts are always expressed in PEN.
+type WithholdingDocument struct {
+	DocType       string          `json:"tipo_comprobante"` // Catalog 01: 01 factura, 03 boleta, 07, 08, 12...
+	Series        string          `json:"serie"`
+	Number        int             `json:"numero"`
+	IssueDate     time.Time       `json:"fecha_emision"`
+	Currency      string          `json:"moneda"`
+	Total         decimal.Decimal `json:"importe_total"`
+	PaymentNumber int             `json:"numero_pago"`  // 1-based, for documents paid in several payments
+	PaymentAmount decimal.Decimal `json:"importe_pago"` // In the currency of the document, without the retention or perception
+	PaymentDate   time.Time       `json:"fecha_pago"`
+	ExchangeRate  decimal.Decimal `json:"tipo_cambio,omitzero"` // Of the payment date, looked up for documents in foreign currency
+	Amount        decimal.Decimal `json:"importe"`              // Retained or perceived amount in PEN
+	NetAmount     decimal.Decimal `json:"importe_neto"`         // Net paid or cashed in PEN
+}
+
+// ReferenceID returns the series and number of the document.
+func (d *WithholdingDocument) ReferenceID() string {
+	return fmt.Sprintf("%s-%d", d.Series, d.Number)
+}
+
+// apply computes the retained or perceived amount of the payment at the given percentage. A
+// retention is deducted from the payment while a perception is collected on top of it.
+func (d *WithholdingDocument) apply(percent decimal.Decimal, collected bool) error {
+	if d.Currency == "" {
+		d.Currency = CurrencyPEN
+	}
+	if d.Series == "" || d.Number == 0 || d.IssueDate.IsZero() {
+		return fmt.Errorf("el tipo, serie, número y fecha del comprobante son requeridos")
+	}
+	if err := ValidateCurrency(d.Currency); err != nil {
+		return fmt.Errorf("comprobante %s: %w", d.ReferenceID(), err)
+	}
+	if !d.PaymentAmount.IsPositive() || d.PaymentAmount.GreaterThan(d.Total) {
+		return fmt.Errorf("comprobante %s: el importe del pago debe ser mayor a cero y no superar el total", d.ReferenceID())
+	}
+	if d.PaymentDate.IsZero() {
+		return fmt.Errorf("comprobante %s: la fecha de pago es requerida", d.ReferenceID())
+	}
+	if d.PaymentNumber == 0 {
+		d.PaymentNumber = 1
+	}
+
+	rate := decimal.NewFromInt(1)
+	if d.Currency != CurrencyPEN {
+		if !d.ExchangeRate.IsPositive() {
+			return fmt.Errorf("comprobante %s: el tipo de cambio es requerido para comprobantes en %s", d.ReferenceID(), d.Currency)
+		}
+		rate = d.ExchangeRate
+	}
+
+	payment := Round2(d.PaymentAmount.Mul(rate))
+	d.Amount = Round2(percentOf(payment, percent))
+	if collected {
+		d.NetAmount = Round2(payment.Add(d.Amount))
+	} else {
+		d.NetAmount = Round2(payment.Sub(d.Amount))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IDespatchService defines the interface for remission guide services.
+type IDespatchService interface {
+	Create(guide *domain.DespatchAdvice) (*domain.DespatchAdvice, error)
+	GetStatus(id string) (*domain.DespatchAdvice, error)
+	GetCdr(id string) ([]byte, error)
+}
+
+// DespatchHandler handles the HTTP requests for remission guides.
+type DespatchHandler struct {
+	service IDespatchService
+}
+
+// NewDespatchHandler creates a new DespatchHandler.
+func NewDespatchHandler(s IDespatchService) *DespatchHandler {
+	return &DespatchHandler{service: s}
+}
+
+// CreateDespatchAdvice handles the creation of a new remission guide.
+func (h *DespatchHandler) CreateDespatchAdvice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var guide domain.DespatchAdvice
+	if err := json.NewDecoder(r.Body).Decode(&guide); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.Create(&guide)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create despatch advice: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// HandleDespatchAdvice handles the status lookup and CDR download of a remission guide.
+func (h *DespatchHandler) HandleDespatchAdvice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Extract the guide ID and action from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/despatch-advices/"), "/")
+	if id == "" {
+		http.Error(w, "Despatch advice ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "status":
+		guide, err := h.service.GetStatus(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get despatch advice status: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(guide)
+	case "cdr":
+		cdr, err := h.service.GetCdr(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get despatch advice CDR: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=R-%s.zip", id))
+		w.Write(cdr)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IExchangeRateService defines the interface for the daily exchange rates.
+type IExchangeRateService interface {
+	Import(r io.Reader) (int, error)
+	Lookup(currency string, date time.Time) (*domain.ExchangeRate, error)
+}
+
+// ExchangeRateHandler handles the HTTP requests for exchange rates.
+type ExchangeRateHandler struct {
+	service IExchangeRateService
+}
+
+// NewExchangeRateHandler creates a new ExchangeRateHandler.
+func NewExchangeRateHandler(s IExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: s}
+}
+
+// HandleExchangeRates handles the import of a CSV file of daily rates (POST, as the body or
+// the "file" field of a multipart form) and the lookup of the rate in force on a date
+// (GET ?currency=USD&date=YYYY-MM-DD).
+func (h *ExchangeRateHandler) HandleExchangeRates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var file io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			part, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "file is a required form field", http.StatusBadRequest)
+				return
+			}
+			defer part.Close()
+			file = part
+		}
+
+		imported, err := h.service.Import(file)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to import exchange rates: %v", err), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"imported": imported})
+	case http.MethodGet:
+		currency := strings.ToUpper(r.URL.Query().Get("currency"))
+		date, err := time.Parse("2006-01-02", r.URL.Query().Get("date"))
+		if currency == "" || err != nil {
+			http.Error(w, "currency and date (YYYY-MM-DD) are required query parameters", http.StatusBadRequest)
+			return
+		}
+
+		rate, err := h.service.Lookup(currency, date)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get exchange rate: %v", err), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rate)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
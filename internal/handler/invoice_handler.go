@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // IInvoiceService defines the interface for invoice services.
@@ -16,6 +18,16 @@ type IInvoiceService interface {
 	CreateDebitNote(dn *domain.DebitNote) (*domain.DebitNote, error)
 	GetDocumentStatus(id string) (string, error)
 	GetDocumentStatusCdr(ruc, docType, series, number string) (*sunat.StatusCdr, error)
+	PerceptionReport(ruc string, from, to time.Time) ([]domain.PerceptionReportLine, error)
+	DocumentBalance(id string) (*domain.DocumentBalance, error)
+	SendDailySummary(issuer domain.Issuer, referenceDate time.Time) (*domain.DailySummary, error)
+	SetInterestPolicy(policy domain.InterestPolicy) error
+	ListDebitNoteDrafts(ruc, status string) ([]*domain.DebitNoteDraft, error)
+	IssueDebitNoteDraft(id, series string, number int) (*domain.DebitNote, error)
+	DiscardDebitNoteDraft(id string) error
+	StartContingency(contingency domain.Contingency) error
+	EndContingency(ruc string) ([]*domain.ContingencySummary, error)
+	GetContingencySummaryStatus(id string) (*domain.ContingencySummary, error)
 }
 
 // InvoiceHandler handles the HTTP requests for invoices.
@@ -85,7 +97,7 @@ func (h *InvoiceHandler) CreateDebitNote(w http.ResponseWriter, r *http.Request)
 	createdDn, err := h.service.CreateDebitNote(&dn)
 	if err != nil {
 		http.Error(w, "Failed to create debit note", http.StatusInternalServerError)
-		return	
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -93,10 +105,114 @@ func (h *InvoiceHandler) CreateDebitNote(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(createdDn)
 }
 
+// SetInterestPolicy handles the configuration of the late-payment interest of an issuer.
+func (h *InvoiceHandler) SetInterestPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy domain.InterestPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SetInterestPolicy(policy); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid interest policy: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// ListDebitNoteDrafts handles the request to list the interest debit note drafts of an issuer.
+func (h *InvoiceHandler) ListDebitNoteDrafts(w http.ResponseWriter, r *http.Request) {
+	ruc := r.URL.Query().Get("ruc")
+	if ruc == "" {
+		http.Error(w, "ruc is a required query parameter", http.StatusBadRequest)
+		return
+	}
+
+	drafts, err := h.service.ListDebitNoteDrafts(ruc, r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list debit note drafts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drafts)
+}
+
+// HandleDebitNoteDraft handles the review of a draft: issuing or discarding it.
+func (h *InvoiceHandler) HandleDebitNoteDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Extract the draft ID and action from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/debit-note-drafts/"), "/")
+	if id == "" {
+		http.Error(w, "Draft ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "issue":
+		var req struct {
+			Series string `json:"serie"`
+			Number int    `json:"numero"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		dn, err := h.service.IssueDebitNoteDraft(id, req.Series, req.Number)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to issue debit note draft: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(dn)
+	case "discard":
+		if err := h.service.DiscardDebitNoteDraft(id); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to discard debit note draft: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// SendDailySummary handles the request to report the pending boleta notes of a day in a daily summary.
+func (h *InvoiceHandler) SendDailySummary(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Issuer        domain.Issuer `json:"emisor"`
+		ReferenceDate string        `json:"fecha_emision_comprobantes"` // YYYY-MM-DD
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	referenceDate, err := time.ParseInLocation("2006-01-02", req.ReferenceDate, time.Local)
+	if err != nil || req.Issuer.RUC == "" {
+		http.Error(w, "emisor and fecha_emision_comprobantes (YYYY-MM-DD) are required", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.SendDailySummary(req.Issuer, referenceDate)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to send daily summary: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(summary)
+}
+
 // GetDocumentStatus handles the request to get the status of a document.
 func (h *InvoiceHandler) GetDocumentStatus(w http.ResponseWriter, r *http.Request) {
 	// Extract the document ID from the URL path.
-	id := r.URL.Path[len("/api/v1/documents/"):len(r.URL.Path)-len("/status")]
+	id := r.URL.Path[len("/api/v1/documents/") : len(r.URL.Path)-len("/status")]
 	if id == "" {
 		http.Error(w, "Document ID is required", http.StatusBadRequest)
 		return
@@ -112,6 +228,29 @@ func (h *InvoiceHandler) GetDocumentStatus(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(map[string]string{"status": status})
 }
 
+// GetInvoiceBalance handles the request to get the net balance of an invoice after its notes.
+func (h *InvoiceHandler) GetInvoiceBalance(w http.ResponseWriter, r *http.Request) {
+	// Extract the invoice ID from the URL path.
+	if !strings.HasSuffix(r.URL.Path, "/balance") {
+		http.NotFound(w, r)
+		return
+	}
+	id := r.URL.Path[len("/api/v1/invoices/") : len(r.URL.Path)-len("/balance")]
+	if id == "" {
+		http.Error(w, "Invoice ID is required", http.StatusBadRequest)
+		return
+	}
+
+	balance, err := h.service.DocumentBalance(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get invoice balance: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
 // GetDocumentStatusCdr handles the request to get the status and CDR of a document.
 func (h *InvoiceHandler) GetDocumentStatusCdr(w http.ResponseWriter, r *http.Request) {
 	// Extract parameters from query string.
@@ -133,4 +272,83 @@ func (h *InvoiceHandler) GetDocumentStatusCdr(w http.ResponseWriter, r *http.Req
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(statusCdr)
-}
\ No newline at end of file
+}
+
+// GetPerceptionReport handles the request to list the perceptions charged in a period.
+func (h *InvoiceHandler) GetPerceptionReport(w http.ResponseWriter, r *http.Request) {
+	// Extract parameters from query string.
+	ruc := r.URL.Query().Get("ruc")
+	from, errFrom := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	to, errTo := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+
+	if ruc == "" || errFrom != nil || errTo != nil {
+		http.Error(w, "ruc, from and to (YYYY-MM-DD) are required query parameters", http.StatusBadRequest)
+		return
+	}
+
+	// Include the whole last day.
+	report, err := h.service.PerceptionReport(ruc, from, to.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get perception report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleContingency handles the start (POST) and the end (DELETE ?ruc=...) of the contingency
+// of an issuer. Ending it reports the documents issued in contingency in RF summaries.
+func (h *InvoiceHandler) HandleContingency(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var contingency domain.Contingency
+		if err := json.NewDecoder(r.Body).Decode(&contingency); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := h.service.StartContingency(contingency); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid contingency: %v", err), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(contingency)
+	case http.MethodDelete:
+		ruc := r.URL.Query().Get("ruc")
+		if ruc == "" {
+			http.Error(w, "ruc is a required query parameter", http.StatusBadRequest)
+			return
+		}
+		summaries, err := h.service.EndContingency(ruc)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to report contingency documents: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summaries)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetContingencySummaryStatus handles the status lookup of a contingency summary.
+func (h *InvoiceHandler) GetContingencySummaryStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Extract the summary ID from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/contingency-summaries/"), "/")
+	if id == "" || action != "status" {
+		http.NotFound(w, r)
+		return
+	}
+
+	summary, err := h.service.GetContingencySummaryStatus(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get contingency summary status: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
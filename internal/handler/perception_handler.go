@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IPerceptionService defines the interface for perception receipt services.
+type IPerceptionService interface {
+	Create(receipt *domain.PerceptionReceipt) (*domain.PerceptionReceipt, error)
+	GetStatus(id string) (*domain.PerceptionReceipt, error)
+	List(ruc string, from, to time.Time) ([]*domain.PerceptionReceipt, error)
+}
+
+// PerceptionHandler handles the HTTP requests for perception receipts.
+type PerceptionHandler struct {
+	service  IPerceptionService
+	reversal IReversalService
+}
+
+// NewPerceptionHandler creates a new PerceptionHandler.
+func NewPerceptionHandler(s IPerceptionService, reversal IReversalService) *PerceptionHandler {
+	return &PerceptionHandler{service: s, reversal: reversal}
+}
+
+// HandlePerceptions handles the creation (POST) and listing (GET) of perception receipts.
+func (h *PerceptionHandler) HandlePerceptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.createPerception(w, r)
+	case http.MethodGet:
+		h.listPerceptions(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PerceptionHandler) createPerception(w http.ResponseWriter, r *http.Request) {
+	var receipt domain.PerceptionReceipt
+	if err := json.NewDecoder(r.Body).Decode(&receipt); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.Create(&receipt)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create perception receipt: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *PerceptionHandler) listPerceptions(w http.ResponseWriter, r *http.Request) {
+	ruc := r.URL.Query().Get("ruc")
+	from, errFrom := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	to, errTo := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+
+	if ruc == "" || errFrom != nil || errTo != nil {
+		http.Error(w, "ruc, from and to (YYYY-MM-DD) are required query parameters", http.StatusBadRequest)
+		return
+	}
+
+	// Include the whole last day.
+	receipts, err := h.service.List(ruc, from, to.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list perception receipts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipts)
+}
+
+// HandlePerception handles the status lookup (GET /{id}/status) and the reversal
+// (POST /{id}/revert) of a perception receipt.
+func (h *PerceptionHandler) HandlePerception(w http.ResponseWriter, r *http.Request) {
+	// Extract the receipt ID and action from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/perceptions/"), "/")
+	if id == "" {
+		http.Error(w, "Perception receipt ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "status" && r.Method == http.MethodGet:
+		receipt, err := h.service.GetStatus(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get perception receipt status: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(receipt)
+	case action == "revert" && r.Method == http.MethodPost:
+		writeReversal(w, r, func(reason string) (*domain.ReversalSummary, error) {
+			return h.reversal.RevertPerception(id, reason)
+		})
+	case action == "status" || action == "revert":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
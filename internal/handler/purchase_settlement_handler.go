@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IPurchaseSettlementService defines the interface for purchase settlement services.
+type IPurchaseSettlementService interface {
+	Create(settlement *domain.PurchaseSettlement) (*domain.PurchaseSettlement, error)
+	GetByID(id string) (*domain.PurchaseSettlement, error)
+}
+
+// PurchaseSettlementHandler handles the HTTP requests for purchase settlements.
+type PurchaseSettlementHandler struct {
+	service IPurchaseSettlementService
+}
+
+// NewPurchaseSettlementHandler creates a new PurchaseSettlementHandler.
+func NewPurchaseSettlementHandler(s IPurchaseSettlementService) *PurchaseSettlementHandler {
+	return &PurchaseSettlementHandler{service: s}
+}
+
+// CreatePurchaseSettlement handles the creation of a new purchase settlement.
+func (h *PurchaseSettlementHandler) CreatePurchaseSettlement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var settlement domain.PurchaseSettlement
+	if err := json.NewDecoder(r.Body).Decode(&settlement); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.Create(&settlement)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create purchase settlement: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetPurchaseSettlement returns a purchase settlement by its ID.
+func (h *PurchaseSettlementHandler) GetPurchaseSettlement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/purchase-settlements/")
+	if id == "" {
+		http.Error(w, "Purchase settlement ID is required", http.StatusBadRequest)
+		return
+	}
+
+	settlement, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get purchase settlement: %v", err), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settlement)
+}
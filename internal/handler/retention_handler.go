@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IRetentionService defines the interface for retention certificate services.
+type IRetentionService interface {
+	Create(retention *domain.Retention) (*domain.Retention, error)
+	GetByID(id string) (*domain.Retention, error)
+}
+
+// RetentionHandler handles the HTTP requests for retention certificates.
+type RetentionHandler struct {
+	service  IRetentionService
+	reversal IReversalService
+}
+
+// NewRetentionHandler creates a new RetentionHandler.
+func NewRetentionHandler(s IRetentionService, reversal IReversalService) *RetentionHandler {
+	return &RetentionHandler{service: s, reversal: reversal}
+}
+
+// CreateRetention handles the creation of a new retention certificate.
+func (h *RetentionHandler) CreateRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var retention domain.Retention
+	if err := json.NewDecoder(r.Body).Decode(&retention); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.Create(&retention)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create retention: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// HandleRetention handles the lookup (GET /{id}) and the reversal (POST /{id}/revert) of a
+// retention certificate.
+func (h *RetentionHandler) HandleRetention(w http.ResponseWriter, r *http.Request) {
+	// Extract the retention ID and action from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/retentions/"), "/")
+	if id == "" {
+		http.Error(w, "Retention ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		retention, err := h.service.GetByID(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get retention: %v", err), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(retention)
+	case action == "revert" && r.Method == http.MethodPost:
+		writeReversal(w, r, func(reason string) (*domain.ReversalSummary, error) {
+			return h.reversal.RevertRetention(id, reason)
+		})
+	case action == "" || action == "revert":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"FacturacionSunat/internal/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IReversalService defines the interface for the reversal of retention and perception certificates.
+type IReversalService interface {
+	RevertRetention(id, reason string) (*domain.ReversalSummary, error)
+	RevertPerception(id, reason string) (*domain.ReversalSummary, error)
+	GetStatus(id string) (*domain.ReversalSummary, error)
+}
+
+// RevertRequest is the body of a reversal request.
+type RevertRequest struct {
+	Reason string `json:"motivo"`
+}
+
+// ReversalHandler handles the HTTP requests for reversal summaries.
+type ReversalHandler struct {
+	service IReversalService
+}
+
+// NewReversalHandler creates a new ReversalHandler.
+func NewReversalHandler(s IReversalService) *ReversalHandler {
+	return &ReversalHandler{service: s}
+}
+
+// GetReversalStatus handles the status lookup of a reversal summary.
+func (h *ReversalHandler) GetReversalStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Extract the summary ID from the URL path.
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/reversals/"), "/")
+	if id == "" || action != "status" {
+		http.NotFound(w, r)
+		return
+	}
+
+	summary, err := h.service.GetStatus(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get reversal summary status: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// writeReversal decodes the reversal request, reverts the certificate and writes the sent summary.
+func writeReversal(w http.ResponseWriter, r *http.Request, revert func(reason string) (*domain.ReversalSummary, error)) {
+	var req RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := revert(req.Reason)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to revert document: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(summary)
+}
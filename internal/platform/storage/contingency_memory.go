@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ContingencyMemoryRepo is an in-memory implementation of the ContingencyRepository.
+type ContingencyMemoryRepo struct {
+	mu        sync.RWMutex
+	numbers   map[string]int                      // Last number by issuer, type and series
+	pending   map[string][]domain.ContingencyLine // By issuer RUC
+	summaries map[string]*domain.ContingencySummary
+}
+
+// NewContingencyMemoryRepo creates a new ContingencyMemoryRepo.
+func NewContingencyMemoryRepo() *ContingencyMemoryRepo {
+	return &ContingencyMemoryRepo{
+		numbers:   make(map[string]int),
+		pending:   make(map[string][]domain.ContingencyLine),
+		summaries: make(map[string]*domain.ContingencySummary),
+	}
+}
+
+// NextNumber implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) NextNumber(ctx context.Context, ruc, docType, series string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := ruc + "-" + docType + "-" + series
+	r.numbers[key]++
+	return r.numbers[key], nil
+}
+
+// AddPendingLine implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) AddPendingLine(ctx context.Context, ruc string, line domain.ContingencyLine) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending[ruc] = append(r.pending[ruc], line)
+	fmt.Printf("ENCOLANDO %s-%d del emisor %s para el resumen de contingencia en memoria...\n", line.Series, line.Number, ruc)
+	return nil
+}
+
+// FindPendingLines implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) FindPendingLines(ctx context.Context, ruc string) ([]domain.ContingencyLine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := append([]domain.ContingencyLine(nil), r.pending[ruc]...)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].IssueDate.Before(result[j].IssueDate) })
+	return result, nil
+}
+
+// Save implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) Save(ctx context.Context, summary *domain.ContingencySummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.summaries[summary.ID]; ok {
+		return fmt.Errorf("resumen de contingencia %s ya existe", summary.ID)
+	}
+	r.summaries[summary.ID] = summary
+
+	remaining := r.pending[summary.RUC][:0]
+	for _, line := range r.pending[summary.RUC] {
+		if !reported(summary, line) {
+			remaining = append(remaining, line)
+		}
+	}
+	r.pending[summary.RUC] = remaining
+	fmt.Printf("GUARDANDO resumen de contingencia %s en memoria...\n", summary.ID)
+	return nil
+}
+
+// FindByID implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) FindByID(ctx context.Context, id string) (*domain.ContingencySummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	summary, ok := r.summaries[id]
+	if !ok {
+		return nil, fmt.Errorf("resumen de contingencia %s no encontrado", id)
+	}
+	return summary, nil
+}
+
+// UpdateStatus implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary, ok := r.summaries[id]
+	if !ok {
+		return fmt.Errorf("resumen de contingencia %s no encontrado para actualizar estado", id)
+	}
+	summary.Status = status
+	if ticketID != "" {
+		summary.TicketID = ticketID
+	}
+	summary.StatusMessage = statusMessage
+	fmt.Printf("ACTUALIZANDO estado del resumen de contingencia %s a %s en memoria...\n", id, status)
+	return nil
+}
+
+// CountByIssueDate implements the domain.ContingencyRepository interface.
+func (r *ContingencyMemoryRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, summary := range r.summaries {
+		if summary.RUC == ruc && sameDay(summary.IssueDate, day) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// reported reports whether the document of the line is included in the contingency summary.
+func reported(summary *domain.ContingencySummary, line domain.ContingencyLine) bool {
+	for _, l := range summary.Lines {
+		if l.DocType == line.DocType && l.Series == line.Series && l.Number == line.Number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ContingencyPostgresRepo is a PostgreSQL implementation of the ContingencyRepository.
+type ContingencyPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewContingencyPostgresRepo creates a new ContingencyPostgresRepo.
+func NewContingencyPostgresRepo( /*db *pgxpool.Pool*/ ) *ContingencyPostgresRepo {
+	return &ContingencyPostgresRepo{ /*db: db*/ }
+}
+
+// NextNumber implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) NextNumber(ctx context.Context, ruc, docType, series string) (int, error) {
+	fmt.Printf("RESERVANDO número de la serie de contingencia %s del emisor %s en PostgreSQL...\n", series, ruc)
+	// Here you would write the SQL UPDATE ... RETURNING statement on the series counter.
+	return 1, nil
+}
+
+// AddPendingLine implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) AddPendingLine(ctx context.Context, ruc string, line domain.ContingencyLine) error {
+	fmt.Printf("ENCOLANDO %s-%d del emisor %s para el resumen de contingencia en PostgreSQL...\n", line.Series, line.Number, ruc)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindPendingLines implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) FindPendingLines(ctx context.Context, ruc string) ([]domain.ContingencyLine, error) {
+	fmt.Printf("BUSCANDO comprobantes de contingencia pendientes del emisor %s en PostgreSQL...\n", ruc)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// Save implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) Save(ctx context.Context, summary *domain.ContingencySummary) error {
+	fmt.Printf("GUARDANDO resumen de contingencia %s en PostgreSQL...\n", summary.ID)
+	// Here you would write the SQL INSERT statement and mark its lines as reported.
+	return nil
+}
+
+// FindByID implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) FindByID(ctx context.Context, id string) (*domain.ContingencySummary, error) {
+	fmt.Printf("BUSCANDO resumen de contingencia %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.ContingencySummary{ID: id}, nil
+}
+
+// UpdateStatus implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	fmt.Printf("ACTUALIZANDO estado del resumen de contingencia %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
+
+// CountByIssueDate implements the domain.ContingencyRepository interface.
+func (r *ContingencyPostgresRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	fmt.Printf("CONTANDO resúmenes de contingencia del emisor %s del %s en PostgreSQL...\n", ruc, day.Format("2006-01-02"))
+	// Here you would write the SQL SELECT COUNT statement.
+	return 0, nil
+}
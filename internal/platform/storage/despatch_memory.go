@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DespatchAdviceMemoryRepo is an in-memory implementation of the DespatchAdviceRepository.
+type DespatchAdviceMemoryRepo struct {
+	mu     sync.RWMutex
+	guides map[string]*domain.DespatchAdvice
+}
+
+// NewDespatchAdviceMemoryRepo creates a new DespatchAdviceMemoryRepo.
+func NewDespatchAdviceMemoryRepo() *DespatchAdviceMemoryRepo {
+	return &DespatchAdviceMemoryRepo{
+		guides: make(map[string]*domain.DespatchAdvice),
+	}
+}
+
+// Save implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdviceMemoryRepo) Save(ctx context.Context, guide *domain.DespatchAdvice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.guides[guide.ID]; ok {
+		return fmt.Errorf("guía de remisión con ID %s ya existe", guide.ID)
+	}
+	r.guides[guide.ID] = guide
+	fmt.Printf("GUARDANDO guía de remisión %s-%d en memoria...\n", guide.Series, guide.Number)
+	return nil
+}
+
+// FindByID implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdviceMemoryRepo) FindByID(ctx context.Context, id string) (*domain.DespatchAdvice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	guide, ok := r.guides[id]
+	if !ok {
+		return nil, fmt.Errorf("guía de remisión con ID %s no encontrada", id)
+	}
+	return guide, nil
+}
+
+// UpdateStatus implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdviceMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	guide, ok := r.guides[id]
+	if !ok {
+		return fmt.Errorf("guía de remisión con ID %s no encontrada para actualizar estado", id)
+	}
+	guide.Status = status
+	guide.TicketID = ticketID
+	guide.StatusError = statusError
+	fmt.Printf("ACTUALIZANDO estado de guía de remisión %s a %s en memoria...\n", id, status)
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+)
+
+// DespatchAdvicePostgresRepo is a PostgreSQL implementation of the DespatchAdviceRepository.
+type DespatchAdvicePostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewDespatchAdvicePostgresRepo creates a new DespatchAdvicePostgresRepo.
+func NewDespatchAdvicePostgresRepo( /*db *pgxpool.Pool*/ ) *DespatchAdvicePostgresRepo {
+	return &DespatchAdvicePostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdvicePostgresRepo) Save(ctx context.Context, guide *domain.DespatchAdvice) error {
+	fmt.Printf("GUARDANDO guía de remisión %s-%d en PostgreSQL...\n", guide.Series, guide.Number)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdvicePostgresRepo) FindByID(ctx context.Context, id string) (*domain.DespatchAdvice, error) {
+	fmt.Printf("BUSCANDO guía de remisión %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.DespatchAdvice{ID: id}, nil
+}
+
+// UpdateStatus implements the domain.DespatchAdviceRepository interface.
+func (r *DespatchAdvicePostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusError string) error {
+	fmt.Printf("ACTUALIZANDO estado de guía de remisión %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
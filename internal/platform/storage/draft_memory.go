@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DebitNoteDraftMemoryRepo is an in-memory implementation of the DebitNoteDraftRepository.
+type DebitNoteDraftMemoryRepo struct {
+	mu     sync.RWMutex
+	drafts map[string]*domain.DebitNoteDraft
+}
+
+// NewDebitNoteDraftMemoryRepo creates a new DebitNoteDraftMemoryRepo.
+func NewDebitNoteDraftMemoryRepo() *DebitNoteDraftMemoryRepo {
+	return &DebitNoteDraftMemoryRepo{
+		drafts: make(map[string]*domain.DebitNoteDraft),
+	}
+}
+
+// Save implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftMemoryRepo) Save(ctx context.Context, draft *domain.DebitNoteDraft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.drafts[draft.ID]; ok {
+		return fmt.Errorf("borrador con ID %s ya existe", draft.ID)
+	}
+	r.drafts[draft.ID] = draft
+	fmt.Printf("GUARDANDO borrador de nota de débito %s en memoria...\n", draft.ID)
+	return nil
+}
+
+// FindByID implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftMemoryRepo) FindByID(ctx context.Context, id string) (*domain.DebitNoteDraft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	draft, ok := r.drafts[id]
+	if !ok {
+		return nil, fmt.Errorf("borrador con ID %s no encontrado", id)
+	}
+	return draft, nil
+}
+
+// FindByInvoice implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftMemoryRepo) FindByInvoice(ctx context.Context, invoiceID string) ([]*domain.DebitNoteDraft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.DebitNoteDraft
+	for _, draft := range r.drafts {
+		if draft.InvoiceID == invoiceID {
+			result = append(result, draft)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// FindByStatus implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftMemoryRepo) FindByStatus(ctx context.Context, ruc, status string) ([]*domain.DebitNoteDraft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.DebitNoteDraft
+	for _, draft := range r.drafts {
+		if draft.DebitNote.Issuer.RUC == ruc && (status == "" || draft.Status == status) {
+			result = append(result, draft)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// UpdateStatus implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftMemoryRepo) UpdateStatus(ctx context.Context, id, status, debitNoteID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	draft, ok := r.drafts[id]
+	if !ok {
+		return fmt.Errorf("borrador con ID %s no encontrado para actualizar estado", id)
+	}
+	draft.Status = status
+	draft.DebitNoteID = debitNoteID
+	fmt.Printf("ACTUALIZANDO estado de borrador %s a %s en memoria...\n", id, status)
+	return nil
+}
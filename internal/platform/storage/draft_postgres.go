@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+)
+
+// DebitNoteDraftPostgresRepo is a PostgreSQL implementation of the DebitNoteDraftRepository.
+type DebitNoteDraftPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewDebitNoteDraftPostgresRepo creates a new DebitNoteDraftPostgresRepo.
+func NewDebitNoteDraftPostgresRepo( /*db *pgxpool.Pool*/ ) *DebitNoteDraftPostgresRepo {
+	return &DebitNoteDraftPostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftPostgresRepo) Save(ctx context.Context, draft *domain.DebitNoteDraft) error {
+	fmt.Printf("GUARDANDO borrador de nota de débito %s en PostgreSQL...\n", draft.ID)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftPostgresRepo) FindByID(ctx context.Context, id string) (*domain.DebitNoteDraft, error) {
+	fmt.Printf("BUSCANDO borrador de nota de débito %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.DebitNoteDraft{ID: id}, nil
+}
+
+// FindByInvoice implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftPostgresRepo) FindByInvoice(ctx context.Context, invoiceID string) ([]*domain.DebitNoteDraft, error) {
+	fmt.Printf("BUSCANDO borradores de la factura %s en PostgreSQL...\n", invoiceID)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// FindByStatus implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftPostgresRepo) FindByStatus(ctx context.Context, ruc, status string) ([]*domain.DebitNoteDraft, error) {
+	fmt.Printf("BUSCANDO borradores %s del emisor %s en PostgreSQL...\n", status, ruc)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// UpdateStatus implements the domain.DebitNoteDraftRepository interface.
+func (r *DebitNoteDraftPostgresRepo) UpdateStatus(ctx context.Context, id, status, debitNoteID string) error {
+	fmt.Printf("ACTUALIZANDO estado de borrador %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExchangeRateMemoryRepo is an in-memory implementation of the ExchangeRateRepository.
+type ExchangeRateMemoryRepo struct {
+	mu    sync.RWMutex
+	rates map[string][]domain.ExchangeRate // By currency, sorted by date
+}
+
+// NewExchangeRateMemoryRepo creates a new ExchangeRateMemoryRepo.
+func NewExchangeRateMemoryRepo() *ExchangeRateMemoryRepo {
+	return &ExchangeRateMemoryRepo{
+		rates: make(map[string][]domain.ExchangeRate),
+	}
+}
+
+// SaveAll implements the domain.ExchangeRateRepository interface.
+func (r *ExchangeRateMemoryRepo) SaveAll(ctx context.Context, rates []domain.ExchangeRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rate := range rates {
+		stored := r.rates[rate.Currency]
+		i := sort.Search(len(stored), func(i int) bool { return !stored[i].Date.Before(rate.Date) })
+		if i < len(stored) && stored[i].Date.Equal(rate.Date) {
+			stored[i] = rate
+			continue
+		}
+		stored = append(stored, domain.ExchangeRate{})
+		copy(stored[i+1:], stored[i:])
+		stored[i] = rate
+		r.rates[rate.Currency] = stored
+	}
+	fmt.Printf("GUARDANDO %d tipos de cambio en memoria...\n", len(rates))
+	return nil
+}
+
+// FindLatest implements the domain.ExchangeRateRepository interface.
+func (r *ExchangeRateMemoryRepo) FindLatest(ctx context.Context, currency string, day time.Time) (*domain.ExchangeRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.rates[currency]
+	i := sort.Search(len(stored), func(i int) bool { return stored[i].Date.After(day) })
+	if i == 0 {
+		return nil, fmt.Errorf("no existe tipo de cambio de %s al %s", currency, day.Format("2006-01-02"))
+	}
+	rate := stored[i-1]
+	return &rate, nil
+}
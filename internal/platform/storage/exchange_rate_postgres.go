@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExchangeRatePostgresRepo is a PostgreSQL implementation of the ExchangeRateRepository.
+type ExchangeRatePostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewExchangeRatePostgresRepo creates a new ExchangeRatePostgresRepo.
+func NewExchangeRatePostgresRepo( /*db *pgxpool.Pool*/ ) *ExchangeRatePostgresRepo {
+	return &ExchangeRatePostgresRepo{ /*db: db*/ }
+}
+
+// SaveAll implements the domain.ExchangeRateRepository interface.
+func (r *ExchangeRatePostgresRepo) SaveAll(ctx context.Context, rates []domain.ExchangeRate) error {
+	fmt.Printf("GUARDANDO %d tipos de cambio en PostgreSQL...\n", len(rates))
+	// Here you would write the SQL INSERT ... ON CONFLICT (currency, date) DO UPDATE statement.
+	return nil
+}
+
+// FindLatest implements the domain.ExchangeRateRepository interface.
+func (r *ExchangeRatePostgresRepo) FindLatest(ctx context.Context, currency string, day time.Time) (*domain.ExchangeRate, error) {
+	fmt.Printf("BUSCANDO tipo de cambio de %s al %s en PostgreSQL...\n", currency, day.Format("2006-01-02"))
+	// Here you would write the SQL SELECT ... WHERE date <= $2 ORDER BY date DESC LIMIT 1 statement.
+	return nil, fmt.Errorf("no existe tipo de cambio de %s al %s", currency, day.Format("2006-01-02"))
+}
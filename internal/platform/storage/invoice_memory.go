@@ -2,9 +2,12 @@ package storage
 
 import (
 	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/pkg/decimal"
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 // InvoiceMemoryRepo is an in-memory implementation of the InvoiceRepository.
@@ -46,6 +49,35 @@ func (r *InvoiceMemoryRepo) FindByID(ctx context.Context, id string) (*domain.In
 	return invoice, nil
 }
 
+// FindByNumber implements the domain.InvoiceRepository interface.
+func (r *InvoiceMemoryRepo) FindByNumber(ctx context.Context, ruc, series string, number int) (*domain.Invoice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invoice := range r.invoices {
+		if invoice.Issuer.RUC == ruc && invoice.Series == series && invoice.Number == number {
+			return invoice, nil
+		}
+	}
+	return nil, fmt.Errorf("factura %s-%d del emisor %s no encontrada", series, number, ruc)
+}
+
+// FindByIssueDate implements the domain.InvoiceRepository interface.
+func (r *InvoiceMemoryRepo) FindByIssueDate(ctx context.Context, ruc string, from, to time.Time) ([]*domain.Invoice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.Issuer.RUC != ruc || invoice.IssueDate.Before(from) || invoice.IssueDate.After(to) {
+			continue
+		}
+		result = append(result, invoice)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IssueDate.Before(result[j].IssueDate) })
+	return result, nil
+}
+
 // UpdateStatus implements the domain.InvoiceRepository interface.
 func (r *InvoiceMemoryRepo) UpdateStatus(ctx context.Context, id string, status string) error {
 	r.mu.Lock()
@@ -59,3 +91,17 @@ func (r *InvoiceMemoryRepo) UpdateStatus(ctx context.Context, id string, status
 	fmt.Printf("ACTUALIZANDO estado de factura %s a %s en memoria...\n", id, status)
 	return nil
 }
+
+// UpdatePrepaidBalance implements the domain.InvoiceRepository interface.
+func (r *InvoiceMemoryRepo) UpdatePrepaidBalance(ctx context.Context, id string, balance decimal.Decimal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, ok := r.invoices[id]
+	if !ok {
+		return fmt.Errorf("factura con ID %s no encontrada para actualizar saldo de anticipo", id)
+	}
+	invoice.PrepaidBalance = balance
+	fmt.Printf("ACTUALIZANDO saldo de anticipo de factura %s a %s en memoria...\n", id, balance)
+	return nil
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/pkg/decimal"
 	"context"
 	"fmt"
+	"time"
 
 	// In a real implementation, you would import the database driver
 	// "github.com/jackc/pgx/v4/pgxpool"
@@ -33,9 +35,30 @@ func (r *InvoicePostgresRepo) FindByID(ctx context.Context, id string) (*domain.
 	return &domain.Invoice{ID: id}, nil
 }
 
+// FindByNumber implements the domain.InvoiceRepository interface.
+func (r *InvoicePostgresRepo) FindByNumber(ctx context.Context, ruc, series string, number int) (*domain.Invoice, error) {
+	fmt.Printf("BUSCANDO factura %s-%d del emisor %s en PostgreSQL...\n", series, number, ruc)
+	// Here you would write the SQL SELECT statement.
+	return &domain.Invoice{Series: series, Number: number}, nil
+}
+
+// FindByIssueDate implements the domain.InvoiceRepository interface.
+func (r *InvoicePostgresRepo) FindByIssueDate(ctx context.Context, ruc string, from, to time.Time) ([]*domain.Invoice, error) {
+	fmt.Printf("BUSCANDO facturas del emisor %s entre %s y %s en PostgreSQL...\n", ruc, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
 // UpdateStatus implements the domain.InvoiceRepository interface.
 func (r *InvoicePostgresRepo) UpdateStatus(ctx context.Context, id string, status string) error {
 	fmt.Printf("ACTUALIZANDO estado de factura %s a %s en PostgreSQL...\n", id, status)
 	// Here you would write the SQL UPDATE statement.
 	return nil
 }
+
+// UpdatePrepaidBalance implements the domain.InvoiceRepository interface.
+func (r *InvoicePostgresRepo) UpdatePrepaidBalance(ctx context.Context, id string, balance decimal.Decimal) error {
+	fmt.Printf("ACTUALIZANDO saldo de anticipo de factura %s a %s en PostgreSQL...\n", id, balance)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// NoteMemoryRepo is an in-memory implementation of the NoteRepository.
+type NoteMemoryRepo struct {
+	mu          sync.RWMutex
+	creditNotes map[string]*domain.CreditNote
+	debitNotes  map[string]*domain.DebitNote
+}
+
+// NewNoteMemoryRepo creates a new NoteMemoryRepo.
+func NewNoteMemoryRepo() *NoteMemoryRepo {
+	return &NoteMemoryRepo{
+		creditNotes: make(map[string]*domain.CreditNote),
+		debitNotes:  make(map[string]*domain.DebitNote),
+	}
+}
+
+// SaveCreditNote implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) SaveCreditNote(ctx context.Context, cn *domain.CreditNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.creditNotes[cn.ID]; ok {
+		return fmt.Errorf("nota de crédito con ID %s ya existe", cn.ID)
+	}
+	r.creditNotes[cn.ID] = cn
+	fmt.Printf("GUARDANDO nota de crédito %s en memoria...\n", cn.ID)
+	return nil
+}
+
+// UpdateCreditNoteStatus implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) UpdateCreditNoteStatus(ctx context.Context, id, status, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cn, ok := r.creditNotes[id]
+	if !ok {
+		return fmt.Errorf("nota de crédito con ID %s no encontrada para actualizar estado", id)
+	}
+	cn.Status = status
+	cn.TicketID = ticketID
+	fmt.Printf("ACTUALIZANDO estado de nota de crédito %s a %s en memoria...\n", id, status)
+	return nil
+}
+
+// SaveDebitNote implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) SaveDebitNote(ctx context.Context, dn *domain.DebitNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.debitNotes[dn.ID]; ok {
+		return fmt.Errorf("nota de débito con ID %s ya existe", dn.ID)
+	}
+	r.debitNotes[dn.ID] = dn
+	fmt.Printf("GUARDANDO nota de débito %s en memoria...\n", dn.ID)
+	return nil
+}
+
+// UpdateDebitNoteStatus implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) UpdateDebitNoteStatus(ctx context.Context, id, status, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dn, ok := r.debitNotes[id]
+	if !ok {
+		return fmt.Errorf("nota de débito con ID %s no encontrada para actualizar estado", id)
+	}
+	dn.Status = status
+	dn.TicketID = ticketID
+	fmt.Printf("ACTUALIZANDO estado de nota de débito %s a %s en memoria...\n", id, status)
+	return nil
+}
+
+// FindCreditNotesByReference implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) FindCreditNotesByReference(ctx context.Context, ruc, series string, number int) ([]*domain.CreditNote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.CreditNote
+	for _, cn := range r.creditNotes {
+		if cn.Issuer.RUC == ruc && referencesDocument(cn.DiscrepancyResponse, series, number) {
+			result = append(result, cn)
+		}
+	}
+	return result, nil
+}
+
+// FindDebitNotesByReference implements the domain.NoteRepository interface.
+func (r *NoteMemoryRepo) FindDebitNotesByReference(ctx context.Context, ruc, series string, number int) ([]*domain.DebitNote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.DebitNote
+	for _, dn := range r.debitNotes {
+		if dn.Issuer.RUC == ruc && referencesDocument(dn.DiscrepancyResponse, series, number) {
+			result = append(result, dn)
+		}
+	}
+	return result, nil
+}
+
+// referencesDocument reports whether the note reason points to the given series and number.
+func referencesDocument(dr domain.DiscrepancyResponse, series string, number int) bool {
+	s, n, err := domain.ParseDocumentReference(dr.ReferenceID)
+	return err == nil && s == series && n == number
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+)
+
+// NotePostgresRepo is a PostgreSQL implementation of the NoteRepository.
+type NotePostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewNotePostgresRepo creates a new NotePostgresRepo.
+func NewNotePostgresRepo( /*db *pgxpool.Pool*/ ) *NotePostgresRepo {
+	return &NotePostgresRepo{ /*db: db*/ }
+}
+
+// SaveCreditNote implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) SaveCreditNote(ctx context.Context, cn *domain.CreditNote) error {
+	fmt.Printf("GUARDANDO nota de crédito %s en PostgreSQL...\n", cn.ID)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// UpdateCreditNoteStatus implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) UpdateCreditNoteStatus(ctx context.Context, id, status, ticketID string) error {
+	fmt.Printf("ACTUALIZANDO estado de nota de crédito %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
+
+// SaveDebitNote implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) SaveDebitNote(ctx context.Context, dn *domain.DebitNote) error {
+	fmt.Printf("GUARDANDO nota de débito %s en PostgreSQL...\n", dn.ID)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// UpdateDebitNoteStatus implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) UpdateDebitNoteStatus(ctx context.Context, id, status, ticketID string) error {
+	fmt.Printf("ACTUALIZANDO estado de nota de débito %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
+
+// FindCreditNotesByReference implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) FindCreditNotesByReference(ctx context.Context, ruc, series string, number int) ([]*domain.CreditNote, error) {
+	fmt.Printf("BUSCANDO notas de crédito del comprobante %s-%d del emisor %s en PostgreSQL...\n", series, number, ruc)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// FindDebitNotesByReference implements the domain.NoteRepository interface.
+func (r *NotePostgresRepo) FindDebitNotesByReference(ctx context.Context, ruc, series string, number int) ([]*domain.DebitNote, error) {
+	fmt.Printf("BUSCANDO notas de débito del comprobante %s-%d del emisor %s en PostgreSQL...\n", series, number, ruc)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PerceptionReceiptMemoryRepo is an in-memory implementation of the PerceptionReceiptRepository.
+type PerceptionReceiptMemoryRepo struct {
+	mu       sync.RWMutex
+	receipts map[string]*domain.PerceptionReceipt
+}
+
+// NewPerceptionReceiptMemoryRepo creates a new PerceptionReceiptMemoryRepo.
+func NewPerceptionReceiptMemoryRepo() *PerceptionReceiptMemoryRepo {
+	return &PerceptionReceiptMemoryRepo{
+		receipts: make(map[string]*domain.PerceptionReceipt),
+	}
+}
+
+// Save implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptMemoryRepo) Save(ctx context.Context, receipt *domain.PerceptionReceipt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.receipts[receipt.ID]; ok {
+		return fmt.Errorf("percepción con ID %s ya existe", receipt.ID)
+	}
+	r.receipts[receipt.ID] = receipt
+	fmt.Printf("GUARDANDO percepción %s-%d en memoria...\n", receipt.Series, receipt.Number)
+	return nil
+}
+
+// FindByID implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptMemoryRepo) FindByID(ctx context.Context, id string) (*domain.PerceptionReceipt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	receipt, ok := r.receipts[id]
+	if !ok {
+		return nil, fmt.Errorf("percepción con ID %s no encontrada", id)
+	}
+	return receipt, nil
+}
+
+// FindByIssuer implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptMemoryRepo) FindByIssuer(ctx context.Context, ruc string, from, to time.Time) ([]*domain.PerceptionReceipt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.PerceptionReceipt
+	for _, receipt := range r.receipts {
+		if receipt.Issuer.RUC != ruc || receipt.IssueDate.Before(from) || receipt.IssueDate.After(to) {
+			continue
+		}
+		result = append(result, receipt)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Series != result[j].Series {
+			return result[i].Series < result[j].Series
+		}
+		return result[i].Number < result[j].Number
+	})
+	return result, nil
+}
+
+// UpdateStatus implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	receipt, ok := r.receipts[id]
+	if !ok {
+		return fmt.Errorf("percepción con ID %s no encontrada para actualizar estado", id)
+	}
+	receipt.Status = status
+	receipt.TicketID = ticketID
+	receipt.StatusMessage = statusMessage
+	fmt.Printf("ACTUALIZANDO estado de percepción %s a %s en memoria...\n", id, status)
+	return nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// PerceptionReceiptPostgresRepo is a PostgreSQL implementation of the PerceptionReceiptRepository.
+type PerceptionReceiptPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewPerceptionReceiptPostgresRepo creates a new PerceptionReceiptPostgresRepo.
+func NewPerceptionReceiptPostgresRepo( /*db *pgxpool.Pool*/ ) *PerceptionReceiptPostgresRepo {
+	return &PerceptionReceiptPostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptPostgresRepo) Save(ctx context.Context, receipt *domain.PerceptionReceipt) error {
+	fmt.Printf("GUARDANDO percepción %s-%d en PostgreSQL...\n", receipt.Series, receipt.Number)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptPostgresRepo) FindByID(ctx context.Context, id string) (*domain.PerceptionReceipt, error) {
+	fmt.Printf("BUSCANDO percepción %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.PerceptionReceipt{ID: id}, nil
+}
+
+// FindByIssuer implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptPostgresRepo) FindByIssuer(ctx context.Context, ruc string, from, to time.Time) ([]*domain.PerceptionReceipt, error) {
+	fmt.Printf("BUSCANDO percepciones del emisor %s en PostgreSQL...\n", ruc)
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// UpdateStatus implements the domain.PerceptionReceiptRepository interface.
+func (r *PerceptionReceiptPostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	fmt.Printf("ACTUALIZANDO estado de percepción %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
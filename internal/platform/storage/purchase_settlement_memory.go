@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PurchaseSettlementMemoryRepo is an in-memory implementation of the PurchaseSettlementRepository.
+type PurchaseSettlementMemoryRepo struct {
+	mu          sync.RWMutex
+	settlements map[string]*domain.PurchaseSettlement
+}
+
+// NewPurchaseSettlementMemoryRepo creates a new PurchaseSettlementMemoryRepo.
+func NewPurchaseSettlementMemoryRepo() *PurchaseSettlementMemoryRepo {
+	return &PurchaseSettlementMemoryRepo{
+		settlements: make(map[string]*domain.PurchaseSettlement),
+	}
+}
+
+// Save implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementMemoryRepo) Save(ctx context.Context, settlement *domain.PurchaseSettlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.settlements[settlement.ID]; ok {
+		return fmt.Errorf("liquidación de compra con ID %s ya existe", settlement.ID)
+	}
+	r.settlements[settlement.ID] = settlement
+	fmt.Printf("GUARDANDO liquidación de compra %s-%d en memoria...\n", settlement.Series, settlement.Number)
+	return nil
+}
+
+// FindByID implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementMemoryRepo) FindByID(ctx context.Context, id string) (*domain.PurchaseSettlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settlement, ok := r.settlements[id]
+	if !ok {
+		return nil, fmt.Errorf("liquidación de compra con ID %s no encontrada", id)
+	}
+	return settlement, nil
+}
+
+// UpdateStatus implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settlement, ok := r.settlements[id]
+	if !ok {
+		return fmt.Errorf("liquidación de compra con ID %s no encontrada para actualizar estado", id)
+	}
+	settlement.Status = status
+	settlement.TicketID = ticketID
+	fmt.Printf("ACTUALIZANDO estado de liquidación de compra %s a %s en memoria...\n", id, status)
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+)
+
+// PurchaseSettlementPostgresRepo is a PostgreSQL implementation of the PurchaseSettlementRepository.
+type PurchaseSettlementPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewPurchaseSettlementPostgresRepo creates a new PurchaseSettlementPostgresRepo.
+func NewPurchaseSettlementPostgresRepo( /*db *pgxpool.Pool*/ ) *PurchaseSettlementPostgresRepo {
+	return &PurchaseSettlementPostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementPostgresRepo) Save(ctx context.Context, settlement *domain.PurchaseSettlement) error {
+	fmt.Printf("GUARDANDO liquidación de compra %s-%d en PostgreSQL...\n", settlement.Series, settlement.Number)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementPostgresRepo) FindByID(ctx context.Context, id string) (*domain.PurchaseSettlement, error) {
+	fmt.Printf("BUSCANDO liquidación de compra %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.PurchaseSettlement{ID: id}, nil
+}
+
+// UpdateStatus implements the domain.PurchaseSettlementRepository interface.
+func (r *PurchaseSettlementPostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID string) error {
+	fmt.Printf("ACTUALIZANDO estado de liquidación de compra %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RetentionMemoryRepo is an in-memory implementation of the RetentionRepository.
+type RetentionMemoryRepo struct {
+	mu         sync.RWMutex
+	retentions map[string]*domain.Retention
+}
+
+// NewRetentionMemoryRepo creates a new RetentionMemoryRepo.
+func NewRetentionMemoryRepo() *RetentionMemoryRepo {
+	return &RetentionMemoryRepo{
+		retentions: make(map[string]*domain.Retention),
+	}
+}
+
+// Save implements the domain.RetentionRepository interface.
+func (r *RetentionMemoryRepo) Save(ctx context.Context, retention *domain.Retention) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.retentions[retention.ID]; ok {
+		return fmt.Errorf("retención con ID %s ya existe", retention.ID)
+	}
+	r.retentions[retention.ID] = retention
+	fmt.Printf("GUARDANDO retención %s-%d en memoria...\n", retention.Series, retention.Number)
+	return nil
+}
+
+// FindByID implements the domain.RetentionRepository interface.
+func (r *RetentionMemoryRepo) FindByID(ctx context.Context, id string) (*domain.Retention, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	retention, ok := r.retentions[id]
+	if !ok {
+		return nil, fmt.Errorf("retención con ID %s no encontrada", id)
+	}
+	return retention, nil
+}
+
+// UpdateStatus implements the domain.RetentionRepository interface.
+func (r *RetentionMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	retention, ok := r.retentions[id]
+	if !ok {
+		return fmt.Errorf("retención con ID %s no encontrada para actualizar estado", id)
+	}
+	retention.Status = status
+	retention.TicketID = ticketID
+	fmt.Printf("ACTUALIZANDO estado de retención %s a %s en memoria...\n", id, status)
+	return nil
+}
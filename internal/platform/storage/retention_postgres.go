@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+)
+
+// RetentionPostgresRepo is a PostgreSQL implementation of the RetentionRepository.
+type RetentionPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewRetentionPostgresRepo creates a new RetentionPostgresRepo.
+func NewRetentionPostgresRepo( /*db *pgxpool.Pool*/ ) *RetentionPostgresRepo {
+	return &RetentionPostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.RetentionRepository interface.
+func (r *RetentionPostgresRepo) Save(ctx context.Context, retention *domain.Retention) error {
+	fmt.Printf("GUARDANDO retención %s-%d en PostgreSQL...\n", retention.Series, retention.Number)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.RetentionRepository interface.
+func (r *RetentionPostgresRepo) FindByID(ctx context.Context, id string) (*domain.Retention, error) {
+	fmt.Printf("BUSCANDO retención %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.Retention{ID: id}, nil
+}
+
+// UpdateStatus implements the domain.RetentionRepository interface.
+func (r *RetentionPostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID string) error {
+	fmt.Printf("ACTUALIZANDO estado de retención %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
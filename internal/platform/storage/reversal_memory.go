@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReversalSummaryMemoryRepo is an in-memory implementation of the ReversalSummaryRepository.
+type ReversalSummaryMemoryRepo struct {
+	mu        sync.RWMutex
+	summaries map[string]*domain.ReversalSummary
+}
+
+// NewReversalSummaryMemoryRepo creates a new ReversalSummaryMemoryRepo.
+func NewReversalSummaryMemoryRepo() *ReversalSummaryMemoryRepo {
+	return &ReversalSummaryMemoryRepo{
+		summaries: make(map[string]*domain.ReversalSummary),
+	}
+}
+
+// Save implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryMemoryRepo) Save(ctx context.Context, summary *domain.ReversalSummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.summaries[summary.ID]; ok {
+		return fmt.Errorf("resumen de reversiones %s ya existe", summary.ID)
+	}
+	r.summaries[summary.ID] = summary
+	fmt.Printf("GUARDANDO resumen de reversiones %s en memoria...\n", summary.ID)
+	return nil
+}
+
+// FindByID implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryMemoryRepo) FindByID(ctx context.Context, id string) (*domain.ReversalSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	summary, ok := r.summaries[id]
+	if !ok {
+		return nil, fmt.Errorf("resumen de reversiones %s no encontrado", id)
+	}
+	return summary, nil
+}
+
+// UpdateStatus implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryMemoryRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary, ok := r.summaries[id]
+	if !ok {
+		return fmt.Errorf("resumen de reversiones %s no encontrado para actualizar estado", id)
+	}
+	summary.Status = status
+	summary.TicketID = ticketID
+	summary.StatusMessage = statusMessage
+	fmt.Printf("ACTUALIZANDO estado del resumen de reversiones %s a %s en memoria...\n", id, status)
+	return nil
+}
+
+// CountByIssueDate implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryMemoryRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, summary := range r.summaries {
+		if summary.Issuer.RUC == ruc && sameDay(summary.IssueDate, day) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReversalSummaryPostgresRepo is a PostgreSQL implementation of the ReversalSummaryRepository.
+type ReversalSummaryPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewReversalSummaryPostgresRepo creates a new ReversalSummaryPostgresRepo.
+func NewReversalSummaryPostgresRepo( /*db *pgxpool.Pool*/ ) *ReversalSummaryPostgresRepo {
+	return &ReversalSummaryPostgresRepo{ /*db: db*/ }
+}
+
+// Save implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryPostgresRepo) Save(ctx context.Context, summary *domain.ReversalSummary) error {
+	fmt.Printf("GUARDANDO resumen de reversiones %s en PostgreSQL...\n", summary.ID)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindByID implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryPostgresRepo) FindByID(ctx context.Context, id string) (*domain.ReversalSummary, error) {
+	fmt.Printf("BUSCANDO resumen de reversiones %s en PostgreSQL...\n", id)
+	// Here you would write the SQL SELECT statement.
+	return &domain.ReversalSummary{ID: id}, nil
+}
+
+// UpdateStatus implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryPostgresRepo) UpdateStatus(ctx context.Context, id, status, ticketID, statusMessage string) error {
+	fmt.Printf("ACTUALIZANDO estado del resumen de reversiones %s a %s en PostgreSQL...\n", id, status)
+	// Here you would write the SQL UPDATE statement.
+	return nil
+}
+
+// CountByIssueDate implements the domain.ReversalSummaryRepository interface.
+func (r *ReversalSummaryPostgresRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	fmt.Printf("CONTANDO resúmenes de reversiones del emisor %s del %s en PostgreSQL...\n", ruc, day.Format("2006-01-02"))
+	// Here you would write the SQL SELECT COUNT statement.
+	return 0, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SummaryMemoryRepo is an in-memory implementation of the SummaryRepository.
+type SummaryMemoryRepo struct {
+	mu        sync.RWMutex
+	pending   map[string][]domain.SummaryLine // By issuer RUC
+	summaries map[string]*domain.DailySummary
+}
+
+// NewSummaryMemoryRepo creates a new SummaryMemoryRepo.
+func NewSummaryMemoryRepo() *SummaryMemoryRepo {
+	return &SummaryMemoryRepo{
+		pending:   make(map[string][]domain.SummaryLine),
+		summaries: make(map[string]*domain.DailySummary),
+	}
+}
+
+// AddPendingLine implements the domain.SummaryRepository interface.
+func (r *SummaryMemoryRepo) AddPendingLine(ctx context.Context, ruc string, line domain.SummaryLine) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending[ruc] = append(r.pending[ruc], line)
+	fmt.Printf("ENCOLANDO %s-%d del emisor %s para el resumen diario en memoria...\n", line.Series, line.Number, ruc)
+	return nil
+}
+
+// FindPendingLines implements the domain.SummaryRepository interface.
+func (r *SummaryMemoryRepo) FindPendingLines(ctx context.Context, ruc string, day time.Time) ([]domain.SummaryLine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []domain.SummaryLine
+	for _, line := range r.pending[ruc] {
+		if sameDay(line.IssueDate, day) {
+			result = append(result, line)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IssueDate.Before(result[j].IssueDate) })
+	return result, nil
+}
+
+// Save implements the domain.SummaryRepository interface.
+func (r *SummaryMemoryRepo) Save(ctx context.Context, summary *domain.DailySummary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.summaries[summary.ID]; ok {
+		return fmt.Errorf("resumen %s ya existe", summary.ID)
+	}
+	r.summaries[summary.ID] = summary
+
+	ruc := summary.Issuer.RUC
+	remaining := r.pending[ruc][:0]
+	for _, line := range r.pending[ruc] {
+		if !summarized(summary, line) {
+			remaining = append(remaining, line)
+		}
+	}
+	r.pending[ruc] = remaining
+	fmt.Printf("GUARDANDO resumen %s en memoria...\n", summary.ID)
+	return nil
+}
+
+// CountByIssueDate implements the domain.SummaryRepository interface.
+func (r *SummaryMemoryRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, summary := range r.summaries {
+		if summary.Issuer.RUC == ruc && sameDay(summary.IssueDate, day) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// summarized reports whether the document of the line is included in the summary.
+func summarized(summary *domain.DailySummary, line domain.SummaryLine) bool {
+	for _, l := range summary.Lines {
+		if l.DocType == line.DocType && l.Series == line.Series && l.Number == line.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDay reports whether both times fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// SummaryPostgresRepo is a PostgreSQL implementation of the SummaryRepository.
+type SummaryPostgresRepo struct {
+	// db *pgxpool.Pool
+}
+
+// NewSummaryPostgresRepo creates a new SummaryPostgresRepo.
+func NewSummaryPostgresRepo( /*db *pgxpool.Pool*/ ) *SummaryPostgresRepo {
+	return &SummaryPostgresRepo{ /*db: db*/ }
+}
+
+// AddPendingLine implements the domain.SummaryRepository interface.
+func (r *SummaryPostgresRepo) AddPendingLine(ctx context.Context, ruc string, line domain.SummaryLine) error {
+	fmt.Printf("ENCOLANDO %s-%d del emisor %s para el resumen diario en PostgreSQL...\n", line.Series, line.Number, ruc)
+	// Here you would write the SQL INSERT statement.
+	return nil
+}
+
+// FindPendingLines implements the domain.SummaryRepository interface.
+func (r *SummaryPostgresRepo) FindPendingLines(ctx context.Context, ruc string, day time.Time) ([]domain.SummaryLine, error) {
+	fmt.Printf("BUSCANDO comprobantes pendientes de resumen del emisor %s del %s en PostgreSQL...\n", ruc, day.Format("2006-01-02"))
+	// Here you would write the SQL SELECT statement.
+	return nil, nil
+}
+
+// Save implements the domain.SummaryRepository interface.
+func (r *SummaryPostgresRepo) Save(ctx context.Context, summary *domain.DailySummary) error {
+	fmt.Printf("GUARDANDO resumen %s en PostgreSQL...\n", summary.ID)
+	// Here you would write the SQL INSERT and UPDATE statements in a transaction.
+	return nil
+}
+
+// CountByIssueDate implements the domain.SummaryRepository interface.
+func (r *SummaryPostgresRepo) CountByIssueDate(ctx context.Context, ruc string, day time.Time) (int, error) {
+	fmt.Printf("CONTANDO resúmenes del emisor %s del %s en PostgreSQL...\n", ruc, day.Format("2006-01-02"))
+	// Here you would write the SQL SELECT COUNT statement.
+	return 0, nil
+}
@@ -45,6 +45,19 @@ type SendBillResponse struct {
 	Ticket  string   `xml:"ticket"`
 }
 
+// SendSummaryRequest represents the SOAP request for sendSummary operation.
+type SendSummaryRequest struct {
+	XMLName     xml.Name `xml:"ser:sendSummary"`
+	FileName    string   `xml:"fileName"`
+	ContentFile string   `xml:"contentFile"` // Base64 encoded ZIP content
+}
+
+// SendSummaryResponse represents the SOAP response for sendSummary operation.
+type SendSummaryResponse struct {
+	XMLName xml.Name `xml:"sendSummaryResponse"`
+	Ticket  string   `xml:"ticket"`
+}
+
 // GetStatusRequest represents the SOAP request for getStatus operation.
 type GetStatusRequest struct {
 	XMLName xml.Name `xml:"ser:getStatus"`
@@ -89,25 +102,13 @@ type StatusCdr struct {
 // SendBill sends a signed XML, zipped, to the SUNAT bill service via SOAP.
 // It returns the ticket ID from SUNAT.
 func (c *Client) SendBill(fileName string, signedXML []byte) (string, error) {
-	// 1. Create a ZIP archive in memory.
-	zipBuffer := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(zipBuffer)
-	xmlFile, err := zipWriter.Create(fileName)
-	if err != nil {
-		return "", fmt.Errorf("error al crear el archivo XML en el zip: %w", err)
-	}
-	_, err = xmlFile.Write(signedXML)
+	// 1. Create a ZIP archive in memory and Base64 encode it.
+	encodedZip, err := zipContent(fileName, signedXML)
 	if err != nil {
-		return "", fmt.Errorf("error al escribir el XML en el zip: %w", err)
-	}
-	if err := zipWriter.Close(); err != nil {
-		return "", fmt.Errorf("error al cerrar el archivo zip: %w", err)
+		return "", err
 	}
 
-	// 2. Base64 encode the ZIP content.
-	encodedZip := base64.StdEncoding.EncodeToString(zipBuffer.Bytes())
-
-	// 3. Prepare the SOAP request.
+	// 2. Prepare the SOAP request.
 	req := &SendBillRequest{
 		FileName:    fileName,
 		ContentFile: encodedZip,
@@ -134,6 +135,65 @@ func (c *Client) SendBill(fileName string, signedXML []byte) (string, error) {
 	return resp.Ticket, nil
 }
 
+// SendSummary sends a signed summary (resumen diario, comunicación de baja), zipped, to the
+// SUNAT bill service via SOAP. SUNAT processes it asynchronously and returns a ticket
+// that must be checked with GetStatus.
+func (c *Client) SendSummary(fileName string, signedXML []byte) (string, error) {
+	encodedZip, err := zipContent(fileName, signedXML)
+	if err != nil {
+		return "", err
+	}
+
+	req := &SendSummaryRequest{
+		FileName:    fileName,
+		ContentFile: encodedZip,
+	}
+	resp := &SendSummaryResponse{}
+
+	fmt.Printf("Enviando resumen %s a SUNAT via SOAP...\n", filepath.Base(fileName))
+	params := gosoap.Params{
+		"fileName":    req.FileName,
+		"contentFile": req.ContentFile,
+	}
+	soapResp, err := c.soapClient.Call("sendSummary", params)
+	if err != nil {
+		return "", fmt.Errorf("error al llamar al servicio sendSummary: %w", err)
+	}
+	if err := soapResp.Unmarshal(&resp); err != nil {
+		return "", fmt.Errorf("error al decodificar respuesta sendSummary: %w", err)
+	}
+
+	fmt.Printf("Ticket de resumen recibido de SUNAT: %s\n", resp.Ticket)
+	return resp.Ticket, nil
+}
+
+// zipContent creates a ZIP archive in memory with the signed XML and returns it Base64 encoded.
+func zipContent(fileName string, signedXML []byte) (string, error) {
+	zipped, err := zipFile(fileName, signedXML)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(zipped), nil
+}
+
+// zipFile creates a ZIP archive in memory that holds the signed XML.
+func zipFile(fileName string, signedXML []byte) ([]byte, error) {
+	zipBuffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(zipBuffer)
+	xmlFile, err := zipWriter.Create(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear el archivo XML en el zip: %w", err)
+	}
+	_, err = xmlFile.Write(signedXML)
+	if err != nil {
+		return nil, fmt.Errorf("error al escribir el XML en el zip: %w", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("error al cerrar el archivo zip: %w", err)
+	}
+	return zipBuffer.Bytes(), nil
+}
+
 // GetStatus checks the status of a previously sent document using its ticket ID via SOAP.
 // It returns the status object from SUNAT.
 func (c *Client) GetStatus(ticketID string) (*Status, error) {
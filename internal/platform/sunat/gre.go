@@ -0,0 +1,187 @@
+package sunat
+
+import (
+	"FacturacionSunat/internal/platform/sunat/oauth"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Response codes of the GRE ticket query.
+const (
+	DespatchAccepted   = "0"  // Processed, the CDR is available
+	DespatchInProgress = "98" // Still being processed
+	DespatchRejected   = "99" // Processed with errors
+)
+
+// GREClient is used to send electronic remission guides (GRE) to the SUNAT REST API.
+// Requests carry the issuer in their context (oauth.WithIssuer) so that an oauth.Transport
+// in the HTTP client authenticates them with the bearer token of that issuer.
+type GREClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGREClient creates a new GRE API client.
+// baseURL is the API root (e.g., "https://api-cpe.sunat.gob.pe/v1/contribuyente/gem").
+func NewGREClient(baseURL string, httpClient *http.Client) *GREClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &GREClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// SendDespatchRequest represents the body of the send operation.
+type SendDespatchRequest struct {
+	File DespatchFile `json:"archivo"`
+}
+
+// DespatchFile is the zipped guide sent to SUNAT.
+type DespatchFile struct {
+	Name    string `json:"nomArchivo"`
+	Content string `json:"arcGreZip"` // Base64 encoded ZIP content
+	Hash    string `json:"hashZip"`   // SHA-256 of the ZIP content, hex encoded
+}
+
+// SendDespatchResponse represents the response of the send operation.
+type SendDespatchResponse struct {
+	Ticket     string `json:"numTicket"`
+	ReceivedAt string `json:"fecRecepcion"`
+}
+
+// DespatchStatus represents the result of a GRE ticket query.
+type DespatchStatus struct {
+	Code         string         `json:"codRespuesta"` // 0 accepted, 98 in progress, 99 rejected
+	Error        *DespatchError `json:"error,omitempty"`
+	Content      string         `json:"arcCdr,omitempty"` // Base64 encoded CDR zip
+	CdrGenerated string         `json:"indCdrGenerado,omitempty"`
+}
+
+// DespatchError is the error reported by SUNAT for a rejected guide.
+type DespatchError struct {
+	Code    string `json:"numError"`
+	Message string `json:"desError"`
+}
+
+// apiError is the error body returned by the SUNAT REST APIs.
+type apiError struct {
+	Code    string `json:"cod"`
+	Message string `json:"msg"`
+	Errors  []struct {
+		Code    string `json:"cod"`
+		Message string `json:"msg"`
+	} `json:"errors"`
+}
+
+// SendDespatch sends a signed guide of the issuer, zipped, to the GRE API.
+// fileName follows RUC-TIPO-SERIE-NUMERO.xml. It returns the ticket ID from SUNAT.
+func (c *GREClient) SendDespatch(ruc, fileName string, signedXML []byte) (string, error) {
+	// 1. Create a ZIP archive in memory, hash it and Base64 encode it.
+	zipped, err := zipFile(fileName, signedXML)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(zipped)
+	name := strings.TrimSuffix(filepath.Base(fileName), ".xml")
+
+	// 2. Prepare the request.
+	req := &SendDespatchRequest{File: DespatchFile{
+		Name:    name + ".zip",
+		Content: base64.StdEncoding.EncodeToString(zipped),
+		Hash:    hex.EncodeToString(hash[:]),
+	}}
+	resp := &SendDespatchResponse{}
+
+	fmt.Printf("Enviando guía %s a SUNAT via REST...\n", name)
+	if err := c.do(ruc, http.MethodPost, "/comprobantes/"+name, req, resp); err != nil {
+		return "", fmt.Errorf("error al enviar la guía de remisión: %w", err)
+	}
+
+	fmt.Printf("Ticket de guía recibido de SUNAT: %s\n", resp.Ticket)
+	return resp.Ticket, nil
+}
+
+// GetDespatchStatus checks the status of a previously sent guide using its ticket ID.
+func (c *GREClient) GetDespatchStatus(ruc, ticketID string) (*DespatchStatus, error) {
+	resp := &DespatchStatus{}
+
+	fmt.Printf("Consultando estado del ticket de guía %s en SUNAT via REST...\n", ticketID)
+	if err := c.do(ruc, http.MethodGet, "/comprobantes/envios/"+ticketID, nil, resp); err != nil {
+		return nil, fmt.Errorf("error al consultar el ticket %s: %w", ticketID, err)
+	}
+
+	fmt.Printf("Estado recibido para ticket de guía %s: %s\n", ticketID, resp.Code)
+	return resp, nil
+}
+
+// GetDespatchCdr retrieves the CDR zip of a processed guide using its ticket ID.
+func (c *GREClient) GetDespatchCdr(ruc, ticketID string) ([]byte, error) {
+	status, err := c.GetDespatchStatus(ruc, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Content == "" {
+		return nil, fmt.Errorf("SUNAT aún no generó el CDR del ticket %s (código %s)", ticketID, status.Code)
+	}
+	cdr, err := base64.StdEncoding.DecodeString(status.Content)
+	if err != nil {
+		return nil, fmt.Errorf("error al decodificar el CDR del ticket %s: %w", ticketID, err)
+	}
+	return cdr, nil
+}
+
+// do sends a JSON request on behalf of the issuer and decodes the JSON response into out.
+func (c *GREClient) do(ruc, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error al codificar la solicitud: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(oauth.WithIssuer(context.Background(), ruc), method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("error al crear la solicitud: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error al leer la respuesta: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			for _, e := range apiErr.Errors {
+				apiErr.Message += fmt.Sprintf("; %s: %s", e.Code, e.Message)
+			}
+			return fmt.Errorf("SUNAT respondió %d (%s): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("SUNAT respondió %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error al decodificar la respuesta: %w", err)
+	}
+	return nil
+}
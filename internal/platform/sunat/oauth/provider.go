@@ -0,0 +1,213 @@
+// Package oauth obtains and caches the OAuth2 tokens that the SUNAT REST APIs (GRE,
+// consulta integrada de CPE, SIRE) require, one per issuer.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the root of the SUNAT security API.
+const DefaultBaseURL = "https://api-seguridad.sunat.gob.pe/v1"
+
+// DefaultScope is the scope of the SUNAT electronic document APIs.
+const DefaultScope = "https://api-cpe.sunat.gob.pe"
+
+// expiryMargin is how long before its expiry a cached token is renewed, so that it does not
+// expire while a request is in flight.
+const expiryMargin = time.Minute
+
+// Credentials are the API credentials an issuer generates in SUNAT Operaciones en Línea.
+// With a SOL user and password the password grant is used, otherwise client credentials.
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+	Username     string // RUC followed by the SOL user, e.g. 20123456789MODDATOS
+	Password     string // SOL password
+	Scope        string // DefaultScope when empty
+}
+
+// grantType returns the OAuth2 grant used with the credentials.
+func (c Credentials) grantType() string {
+	if c.Username != "" {
+		return "password"
+	}
+	return "client_credentials"
+}
+
+// Token is an access token issued by SUNAT.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	ExpiresAt   time.Time
+}
+
+// valid reports whether the token can still be used at the given time.
+func (t *Token) valid(now time.Time) bool {
+	return t != nil && now.Add(expiryMargin).Before(t.ExpiresAt)
+}
+
+// tokenResponse is the body returned by the token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"` // Seconds
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch is an in-flight token request shared by the callers that need the same token.
+type fetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// Provider hands out the tokens of each issuer. Tokens are cached until shortly before
+// they expire and concurrent callers share a single refresh.
+type Provider struct {
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu          sync.Mutex
+	credentials map[string]Credentials
+	tokens      map[string]*Token
+	fetches     map[string]*fetch
+}
+
+// NewProvider creates a token provider for the security API at baseURL (DefaultBaseURL
+// when empty).
+func NewProvider(baseURL string, httpClient *http.Client) *Provider {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Provider{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  httpClient,
+		now:         time.Now,
+		credentials: make(map[string]Credentials),
+		tokens:      make(map[string]*Token),
+		fetches:     make(map[string]*fetch),
+	}
+}
+
+// SetCredentials registers the API credentials of an issuer, discarding its cached token.
+func (p *Provider) SetCredentials(ruc string, c Credentials) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.credentials[ruc] = c
+	delete(p.tokens, ruc)
+}
+
+// Invalidate discards the cached token of an issuer, e.g. after SUNAT rejected it.
+func (p *Provider) Invalidate(ruc string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tokens, ruc)
+}
+
+// Token returns a valid access token of the issuer, requesting a new one when the cached
+// token is missing or about to expire.
+func (p *Provider) Token(ctx context.Context, ruc string) (*Token, error) {
+	p.mu.Lock()
+	if t := p.tokens[ruc]; t.valid(p.now()) {
+		p.mu.Unlock()
+		return t, nil
+	}
+	creds, ok := p.credentials[ruc]
+	if !ok {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("no hay credenciales de API registradas para el emisor %s", ruc)
+	}
+	f, inFlight := p.fetches[ruc]
+	if !inFlight {
+		f = &fetch{done: make(chan struct{})}
+		p.fetches[ruc] = f
+	}
+	p.mu.Unlock()
+
+	if !inFlight {
+		// The request is not bound to the context of the first caller, which could be
+		// canceled while other callers wait for the same token.
+		f.token, f.err = p.request(context.WithoutCancel(ctx), creds)
+		p.mu.Lock()
+		if f.err == nil {
+			p.tokens[ruc] = f.token
+		}
+		delete(p.fetches, ruc)
+		p.mu.Unlock()
+		close(f.done)
+	}
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// request obtains a new token from the security API.
+func (p *Provider) request(ctx context.Context, c Credentials) (*Token, error) {
+	scope := c.Scope
+	if scope == "" {
+		scope = DefaultScope
+	}
+	form := url.Values{
+		"grant_type":    {c.grantType()},
+		"scope":         {scope},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+	}
+	endpoint := fmt.Sprintf("%s/clientesextranet/%s/oauth2/token/", p.baseURL, url.PathEscape(c.ClientID))
+	if c.grantType() == "password" {
+		form.Set("username", c.Username)
+		form.Set("password", c.Password)
+		endpoint = fmt.Sprintf("%s/clientessol/%s/oauth2/token/", p.baseURL, url.PathEscape(c.ClientID))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear la solicitud de token: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	issuedAt := p.now()
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al solicitar el token: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la respuesta del token: %w", err)
+	}
+
+	var body tokenResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("SUNAT respondió %d al solicitar el token: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("SUNAT rechazó la solicitud de token (%d): %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	tokenType := body.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	return &Token{
+		AccessToken: body.AccessToken,
+		TokenType:   tokenType,
+		ExpiresAt:   issuedAt.Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,216 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTokenServer emulates the SUNAT security API. It issues numbered tokens and records
+// the requests it receives.
+type fakeTokenServer struct {
+	*httptest.Server
+	requests  atomic.Int32
+	expiresIn int
+	delay     time.Duration
+
+	mu    sync.Mutex
+	forms []map[string]string
+	paths []string
+}
+
+func newFakeTokenServer(t *testing.T, expiresIn int) *fakeTokenServer {
+	t.Helper()
+	f := &fakeTokenServer{expiresIn: expiresIn}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveToken))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeTokenServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	n := f.requests.Add(1)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	form := make(map[string]string)
+	for k := range r.PostForm {
+		form[k] = r.PostForm.Get(k)
+	}
+	f.mu.Lock()
+	f.forms = append(f.forms, form)
+	f.paths = append(f.paths, r.URL.Path)
+	f.mu.Unlock()
+
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if form["client_secret"] != "secret" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "cliente no autorizado"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": fmt.Sprintf("token-%d", n),
+		"token_type":   "Bearer",
+		"expires_in":   f.expiresIn,
+	})
+}
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestProvider(server *fakeTokenServer) (*Provider, *clock) {
+	c := &clock{now: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+	p := NewProvider(server.URL, server.Client())
+	p.now = c.Now
+	return p, c
+}
+
+func TestTokenPasswordGrant(t *testing.T) {
+	server := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret", Username: "20123456789MODDATOS", Password: "MODDATOS"})
+
+	token, err := p.Token(context.Background(), "20123456789")
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token.AccessToken != "token-1" || token.TokenType != "Bearer" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+
+	form := server.forms[0]
+	if form["grant_type"] != "password" || form["username"] != "20123456789MODDATOS" || form["password"] != "MODDATOS" || form["scope"] != DefaultScope {
+		t.Errorf("unexpected form %v", form)
+	}
+	if want := "/clientessol/abc/oauth2/token/"; server.paths[0] != want {
+		t.Errorf("path = %s, want %s", server.paths[0], want)
+	}
+}
+
+func TestTokenClientCredentialsGrant(t *testing.T) {
+	server := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret", Scope: "https://api-sire.sunat.gob.pe"})
+
+	if _, err := p.Token(context.Background(), "20123456789"); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	form := server.forms[0]
+	if form["grant_type"] != "client_credentials" || form["scope"] != "https://api-sire.sunat.gob.pe" || form["username"] != "" {
+		t.Errorf("unexpected form %v", form)
+	}
+	if want := "/clientesextranet/abc/oauth2/token/"; server.paths[0] != want {
+		t.Errorf("path = %s, want %s", server.paths[0], want)
+	}
+}
+
+func TestTokenCachedUntilExpiry(t *testing.T) {
+	server := newFakeTokenServer(t, 600)
+	p, c := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+	ctx := context.Background()
+
+	first, _ := p.Token(ctx, "20123456789")
+	c.Advance(5 * time.Minute)
+	second, _ := p.Token(ctx, "20123456789")
+	if second.AccessToken != first.AccessToken || server.requests.Load() != 1 {
+		t.Fatalf("token was not cached: %s then %s after %d requests", first.AccessToken, second.AccessToken, server.requests.Load())
+	}
+
+	// Renewed within the expiry margin, before it actually expires.
+	c.Advance(600*time.Second - 5*time.Minute - expiryMargin/2)
+	third, _ := p.Token(ctx, "20123456789")
+	if third.AccessToken == first.AccessToken || server.requests.Load() != 2 {
+		t.Fatalf("token was not renewed before expiry: %s after %d requests", third.AccessToken, server.requests.Load())
+	}
+}
+
+func TestTokenPerIssuer(t *testing.T) {
+	server := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+	p.SetCredentials("20999999999", Credentials{ClientID: "xyz", ClientSecret: "secret"})
+	ctx := context.Background()
+
+	a, _ := p.Token(ctx, "20123456789")
+	b, _ := p.Token(ctx, "20999999999")
+	if a.AccessToken == b.AccessToken {
+		t.Fatalf("issuers share the token %s", a.AccessToken)
+	}
+	if _, err := p.Token(ctx, "20555555555"); err == nil {
+		t.Fatal("expected an error for an issuer without credentials")
+	}
+}
+
+func TestTokenSingleFlight(t *testing.T) {
+	server := newFakeTokenServer(t, 3600)
+	server.delay = 50 * time.Millisecond
+	p, _ := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := p.Token(context.Background(), "20123456789")
+			if err != nil {
+				t.Errorf("Token: %v", err)
+				return
+			}
+			tokens[i] = token.AccessToken
+		}(i)
+	}
+	wg.Wait()
+
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("token server received %d requests, want 1", n)
+	}
+	for _, token := range tokens {
+		if token != "token-1" {
+			t.Fatalf("callers got different tokens: %v", tokens)
+		}
+	}
+}
+
+func TestTokenError(t *testing.T) {
+	server := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(server)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "wrong"})
+
+	_, err := p.Token(context.Background(), "20123456789")
+	if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Fatalf("expected invalid_client error, got %v", err)
+	}
+	// Failures are not cached.
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+	if _, err := p.Token(context.Background(), "20123456789"); err != nil {
+		t.Fatalf("Token after fixing credentials: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// issuerKey is the context key of the issuer a request is made for.
+type issuerKey struct{}
+
+// WithIssuer returns a context whose requests are authenticated with the token of the issuer.
+func WithIssuer(ctx context.Context, ruc string) context.Context {
+	return context.WithValue(ctx, issuerKey{}, ruc)
+}
+
+// IssuerFrom returns the issuer set with WithIssuer, if any.
+func IssuerFrom(ctx context.Context) (string, bool) {
+	ruc, ok := ctx.Value(issuerKey{}).(string)
+	return ruc, ok && ruc != ""
+}
+
+// Transport is an http.RoundTripper that authenticates each request with the bearer token
+// of the issuer found in the request context. When SUNAT rejects a token with 401 the token
+// is discarded and the request retried once with a new one.
+type Transport struct {
+	Provider *Provider
+	Base     http.RoundTripper // http.DefaultTransport when nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ruc, ok := IssuerFrom(req.Context())
+	if !ok {
+		closeBody(req)
+		return nil, fmt.Errorf("la solicitud a %s no indica el emisor para autenticarla", req.URL.Path)
+	}
+
+	resp, err := t.send(req, ruc)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil // The body cannot be sent again
+	}
+
+	resp.Body.Close()
+	t.Provider.Invalidate(ruc)
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.send(retry, ruc)
+}
+
+// send adds the current token of the issuer to a copy of the request and sends it.
+func (t *Transport) send(req *http.Request, ruc string) (*http.Response, error) {
+	token, err := t.Provider.Token(req.Context(), ruc)
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	// A RoundTripper must not modify the request it receives.
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", token.TokenType+" "+token.AccessToken)
+	return t.base().RoundTrip(authorized)
+}
+
+// closeBody closes the body of a request that will not be sent, as a RoundTripper must
+// close it even on errors.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// base returns the underlying RoundTripper.
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
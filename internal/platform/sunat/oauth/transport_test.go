@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransportInjectsBearerToken(t *testing.T) {
+	tokenServer := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(tokenServer)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+
+	var auth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: &Transport{Provider: p}}
+	req, _ := http.NewRequestWithContext(WithIssuer(context.Background(), "20123456789"), http.MethodGet, api.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if auth != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer token-1")
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Error("the transport modified the original request")
+	}
+}
+
+func TestTransportRetriesWithNewTokenOnUnauthorized(t *testing.T) {
+	tokenServer := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(tokenServer)
+	p.SetCredentials("20123456789", Credentials{ClientID: "abc", ClientSecret: "secret"})
+
+	var calls atomic.Int32
+	var bodies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		// The first token was revoked by SUNAT.
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: &Transport{Provider: p}}
+	req, _ := http.NewRequestWithContext(WithIssuer(context.Background(), "20123456789"), http.MethodPost, api.URL, strings.NewReader(`{"archivo":{}}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("status %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+	if bodies[1] != `{"archivo":{}}` {
+		t.Errorf("retried body = %q", bodies[1])
+	}
+}
+
+func TestTransportRequiresIssuer(t *testing.T) {
+	tokenServer := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(tokenServer)
+
+	client := &http.Client{Transport: &Transport{Provider: p}}
+	if _, err := client.Get(tokenServer.URL); err == nil {
+		t.Fatal("expected an error for a request without issuer")
+	}
+}
+
+// trackedBody records whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTransportClosesBodyOnError(t *testing.T) {
+	tokenServer := newFakeTokenServer(t, 3600)
+	p, _ := newTestProvider(tokenServer)
+	transport := &Transport{Provider: p}
+
+	for name, ctx := range map[string]context.Context{
+		"without issuer":      context.Background(),
+		"without credentials": WithIssuer(context.Background(), "20123456789"),
+	} {
+		body := &trackedBody{Reader: strings.NewReader(`{"archivo":{}}`)}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, tokenServer.URL, body)
+		if _, err := transport.RoundTrip(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if !body.closed {
+			t.Errorf("%s: the request body was not closed", name)
+		}
+	}
+}
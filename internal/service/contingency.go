@@ -0,0 +1,171 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// StartContingency puts an issuer in contingency: its new documents take the numeric
+// contingency series and are queued for the RF summary instead of being sent.
+func (s *InvoiceService) StartContingency(contingency domain.Contingency) error {
+	return s.contingencies.Start(contingency)
+}
+
+// EndContingency takes the issuer out of contingency and reports the documents issued in it,
+// one RF summary per issue date. Calling it again retries the documents still pending.
+func (s *InvoiceService) EndContingency(ruc string) ([]*domain.ContingencySummary, error) {
+	s.contingencies.End(ruc)
+
+	ctx := context.Background()
+	lines, err := s.contingencyRepo.FindPendingLines(ctx, ruc)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar comprobantes de contingencia pendientes: %w", err)
+	}
+
+	var summaries []*domain.ContingencySummary
+	for len(lines) > 0 {
+		// Lines are sorted by issue date: report the documents of the first day.
+		n := 1
+		for n < len(lines) && sameDay(lines[n].IssueDate, lines[0].IssueDate) {
+			n++
+		}
+		summary, err := s.sendContingencySummary(ctx, ruc, lines[:n])
+		if err != nil {
+			return summaries, err
+		}
+		summaries = append(summaries, summary)
+		lines = lines[n:]
+	}
+	return summaries, nil
+}
+
+// GetContingencySummaryStatus queries the ticket of an RF summary and reconciles the status
+// of the documents it reports: ACEPTADO or RECHAZADO with the summary.
+func (s *InvoiceService) GetContingencySummaryStatus(id string) (*domain.ContingencySummary, error) {
+	ctx := context.Background()
+	summary, err := s.contingencyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status != "ENVIADO" {
+		return summary, nil
+	}
+
+	status, err := s.sunatClient.GetStatus(summary.TicketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar estado en SUNAT: %w", err)
+	}
+	switch status.StatusCode {
+	case "0": // Processed
+		summary.Status = "ACEPTADO"
+	case "98": // In progress
+		return summary, nil
+	case "99": // Processed with errors
+		summary.Status = "RECHAZADO"
+	default:
+		return nil, fmt.Errorf("código de respuesta %q desconocido para el ticket %s", status.StatusCode, summary.TicketID)
+	}
+
+	for _, line := range summary.Lines {
+		if err := s.invoiceRepo.UpdateStatus(ctx, line.InvoiceID, summary.Status); err != nil {
+			return nil, fmt.Errorf("error al actualizar el estado de %s-%d: %w", line.Series, line.Number, err)
+		}
+	}
+	if err := s.contingencyRepo.UpdateStatus(ctx, summary.ID, summary.Status, summary.TicketID, status.StatusMessage); err != nil {
+		return nil, err
+	}
+	summary.StatusMessage = status.StatusMessage
+	return summary, nil
+}
+
+// allocateContingencyNumber assigns the contingency series of the document type and its next number.
+func (s *InvoiceService) allocateContingencyNumber(ctx context.Context, invoice *domain.Invoice, contingency domain.Contingency) error {
+	series, err := contingency.SeriesFor(invoice.Type)
+	if err != nil {
+		return err
+	}
+	number, err := s.contingencyRepo.NextNumber(ctx, invoice.Issuer.RUC, invoice.Type, series)
+	if err != nil {
+		return fmt.Errorf("error al numerar el comprobante de contingencia: %w", err)
+	}
+	invoice.Series = series
+	invoice.Number = number
+	return nil
+}
+
+// queueContingencyDocument marks a document issued in contingency and queues it for the RF summary.
+func (s *InvoiceService) queueContingencyDocument(ctx context.Context, invoice *domain.Invoice, contingency domain.Contingency) error {
+	invoice.Status = "CONTINGENCIA"
+	if err := s.invoiceRepo.UpdateStatus(ctx, invoice.ID, invoice.Status); err != nil {
+		return err
+	}
+	line := domain.ContingencyLineFromInvoice(invoice, contingency.Reason)
+	if err := s.contingencyRepo.AddPendingLine(ctx, invoice.Issuer.RUC, line); err != nil {
+		return fmt.Errorf("error al encolar el comprobante de contingencia: %w", err)
+	}
+	return nil
+}
+
+// sendContingencySummary sends the RF summary of documents issued on the same day. The summary
+// is saved before it is sent; if SUNAT does not take it, it is rejected and its documents are
+// queued again for the next summary.
+func (s *InvoiceService) sendContingencySummary(ctx context.Context, ruc string, lines []domain.ContingencyLine) (*domain.ContingencySummary, error) {
+	now := time.Now()
+	count, err := s.contingencyRepo.CountByIssueDate(ctx, ruc, now)
+	if err != nil {
+		return nil, fmt.Errorf("error al numerar el resumen de contingencia: %w", err)
+	}
+	summary := &domain.ContingencySummary{
+		ID:            domain.ContingencySummaryID(now, count+1),
+		RUC:           ruc,
+		ReferenceDate: lines[0].IssueDate,
+		IssueDate:     now,
+		Lines:         lines,
+		Status:        "RECIBIDO",
+	}
+	if err := s.contingencyRepo.Save(ctx, summary); err != nil {
+		return nil, fmt.Errorf("error al guardar el resumen de contingencia: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.txt", ruc, summary.ID)
+	ticket, err := s.sunatClient.SendSummary(fileName, summary.Text())
+	if err != nil {
+		s.rejectContingencySummary(ctx, summary, err)
+		return nil, fmt.Errorf("error al enviar el resumen de contingencia a SUNAT: %w", err)
+	}
+
+	if err := s.contingencyRepo.UpdateStatus(ctx, summary.ID, "ENVIADO", ticket, ""); err != nil {
+		return nil, err
+	}
+	summary.TicketID = ticket
+	summary.Status = "ENVIADO"
+	for _, line := range lines {
+		if err := s.invoiceRepo.UpdateStatus(ctx, line.InvoiceID, summary.Status); err != nil {
+			return nil, fmt.Errorf("error al actualizar el estado de %s-%d: %w", line.Series, line.Number, err)
+		}
+	}
+	return summary, nil
+}
+
+// rejectContingencySummary marks a saved RF summary as rejected, with the cause, when it could
+// not be sent, and queues its documents again.
+func (s *InvoiceService) rejectContingencySummary(ctx context.Context, summary *domain.ContingencySummary, cause error) {
+	summary.Status = "RECHAZADO"
+	if err := s.contingencyRepo.UpdateStatus(ctx, summary.ID, summary.Status, "", cause.Error()); err != nil {
+		fmt.Printf("No se pudo actualizar el estado del resumen de contingencia %s: %v\n", summary.ID, err)
+	}
+	for _, line := range summary.Lines {
+		if err := s.contingencyRepo.AddPendingLine(ctx, summary.RUC, line); err != nil {
+			fmt.Printf("No se pudo volver a encolar %s-%d: %v\n", line.Series, line.Number, err)
+		}
+	}
+}
+
+// sameDay reports whether both times fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/internal/platform/signer"
+	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/ubl"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DespatchService is the service for handling electronic remission guides (GRE).
+type DespatchService struct {
+	despatchRepo domain.DespatchAdviceRepository
+	signer       *signer.XMLSigner
+	greClient    *sunat.GREClient
+}
+
+// NewDespatchService creates a new DespatchService.
+func NewDespatchService(repo domain.DespatchAdviceRepository, signer *signer.XMLSigner, greClient *sunat.GREClient) *DespatchService {
+	return &DespatchService{
+		despatchRepo: repo,
+		signer:       signer,
+		greClient:    greClient,
+	}
+}
+
+// Create validates, signs and sends a new remission guide to the GRE API. SUNAT processes
+// guides asynchronously: the returned guide holds the ticket to query with GetStatus.
+func (s *DespatchService) Create(guide *domain.DespatchAdvice) (*domain.DespatchAdvice, error) {
+	guide.ID = uuid.New().String()
+	guide.Status = "RECIBIDO"
+	if guide.IssueDate.IsZero() {
+		guide.IssueDate = time.Now()
+	}
+	if err := guide.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.despatchRepo.Save(context.Background(), guide); err != nil {
+		return nil, fmt.Errorf("error al guardar la guía de remisión: %w", err)
+	}
+
+	ublDespatch, err := ubl.BuildDespatchAdvice(guide)
+	if err != nil {
+		return nil, fmt.Errorf("error al construir UBL de guía de remisión: %w", err)
+	}
+
+	unsignedXML, err := xml.MarshalIndent(ublDespatch, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error al generar XML de guía de remisión: %w", err)
+	}
+
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		return nil, fmt.Errorf("error al firmar XML de guía de remisión: %w", err)
+	}
+	guide.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", guide.Issuer.RUC, guide.Type, guide.Series, guide.Number)
+	ticket, err := s.greClient.SendDespatch(guide.Issuer.RUC, fileName, signedXML)
+	if err != nil {
+		guide.Status = "RECHAZADO"
+		if updateErr := s.despatchRepo.UpdateStatus(context.Background(), guide.ID, guide.Status, "", err.Error()); updateErr != nil {
+			fmt.Printf("No se pudo actualizar el estado de la guía %s: %v\n", guide.ID, updateErr)
+		}
+		return nil, fmt.Errorf("error al enviar guía de remisión a SUNAT: %w", err)
+	}
+
+	if err := s.despatchRepo.UpdateStatus(context.Background(), guide.ID, "ENVIADO", ticket, ""); err != nil {
+		return nil, err
+	}
+	guide.TicketID = ticket
+	guide.Status = "ENVIADO"
+	return guide, nil
+}
+
+// GetStatus queries the ticket of a sent guide and records the result: ACEPTADO when SUNAT
+// generated the CDR, RECHAZADO with the SUNAT error, or ENVIADO while it is in progress.
+func (s *DespatchService) GetStatus(id string) (*domain.DespatchAdvice, error) {
+	guide, err := s.despatchRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if guide.TicketID == "" {
+		return nil, fmt.Errorf("la guía de remisión %s-%d no tiene ticket de SUNAT", guide.Series, guide.Number)
+	}
+
+	status, err := s.greClient.GetDespatchStatus(guide.Issuer.RUC, guide.TicketID)
+	if err != nil {
+		return nil, err
+	}
+	var statusError string
+	switch status.Code {
+	case sunat.DespatchAccepted:
+		guide.Status = "ACEPTADO"
+	case sunat.DespatchRejected:
+		guide.Status = "RECHAZADO"
+		if status.Error != nil {
+			statusError = fmt.Sprintf("%s: %s", status.Error.Code, status.Error.Message)
+		}
+	case sunat.DespatchInProgress:
+		return guide, nil
+	default:
+		return nil, fmt.Errorf("código de respuesta %q desconocido para el ticket %s", status.Code, guide.TicketID)
+	}
+
+	if err := s.despatchRepo.UpdateStatus(context.Background(), guide.ID, guide.Status, guide.TicketID, statusError); err != nil {
+		return nil, err
+	}
+	guide.StatusError = statusError
+	return guide, nil
+}
+
+// GetCdr downloads the CDR zip of a processed guide.
+func (s *DespatchService) GetCdr(id string) ([]byte, error) {
+	guide, err := s.despatchRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if guide.TicketID == "" {
+		return nil, fmt.Errorf("la guía de remisión %s-%d no tiene ticket de SUNAT", guide.Series, guide.Number)
+	}
+	return s.greClient.GetDespatchCdr(guide.Issuer.RUC, guide.TicketID)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/pkg/decimal"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExchangeRateService is the service for importing and looking up the daily exchange rates.
+type ExchangeRateService struct {
+	repo domain.ExchangeRateRepository
+}
+
+// NewExchangeRateService creates a new ExchangeRateService.
+func NewExchangeRateService(repo domain.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+// Import reads the rates of a CSV file with the columns fecha, moneda, compra and venta, e.g.
+// 2024-01-15,USD,3.712,3.718, and stores them. The date may also be written as 15/01/2024 and
+// a header row is skipped. It returns how many rates were imported.
+func (s *ExchangeRateService) Import(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []domain.ExchangeRate
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("archivo de tipos de cambio inválido: %w", err)
+		}
+		if row == 1 && strings.EqualFold(record[0], "fecha") {
+			continue
+		}
+		rate, err := parseExchangeRate(record)
+		if err != nil {
+			return 0, fmt.Errorf("fila %d: %w", row, err)
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return 0, fmt.Errorf("el archivo no contiene tipos de cambio")
+	}
+
+	if err := s.repo.SaveAll(context.Background(), rates); err != nil {
+		return 0, fmt.Errorf("error al guardar los tipos de cambio: %w", err)
+	}
+	return len(rates), nil
+}
+
+// Lookup returns the rate of the currency in force on the given date.
+func (s *ExchangeRateService) Lookup(currency string, date time.Time) (*domain.ExchangeRate, error) {
+	if err := domain.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	return exchangeRate(context.Background(), s.repo, currency, date)
+}
+
+// parseExchangeRate reads a row of the exchange rate file.
+func parseExchangeRate(record []string) (domain.ExchangeRate, error) {
+	date, err := time.Parse("2006-01-02", record[0])
+	if err != nil {
+		if date, err = time.Parse("02/01/2006", record[0]); err != nil {
+			return domain.ExchangeRate{}, fmt.Errorf("fecha %q inválida, se espera AAAA-MM-DD o DD/MM/AAAA", record[0])
+		}
+	}
+	buy, err := decimal.Parse(record[2])
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	sell, err := decimal.Parse(record[3])
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	rate := domain.ExchangeRate{Date: date, Currency: strings.ToUpper(record[1]), Buy: buy, Sell: sell}
+	return rate, rate.Validate()
+}
+
+// exchangeRate returns the rate of the currency in force on the given date: that of the day
+// or, on weekends and holidays, the last one published.
+func exchangeRate(ctx context.Context, repo domain.ExchangeRateRepository, currency string, date time.Time) (*domain.ExchangeRate, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	rate, err := repo.FindLatest(ctx, currency, day)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar el tipo de cambio: %w", err)
+	}
+	if day.Sub(rate.Date) > domain.ExchangeRateMaxAge {
+		return nil, fmt.Errorf("el último tipo de cambio de %s es del %s, importe los tipos de cambio vigentes al %s",
+			currency, rate.Date.Format("2006-01-02"), day.Format("2006-01-02"))
+	}
+	return rate, nil
+}
+
+// penEquivalent returns the totals of a document in soles at the selling rate of its issue
+// date, nil for documents in PEN.
+func penEquivalent(ctx context.Context, repo domain.ExchangeRateRepository, currency string, issueDate time.Time, totals domain.Totals) (*domain.PENEquivalent, error) {
+	if currency == domain.CurrencyPEN {
+		return nil, nil
+	}
+	rate, err := exchangeRate(ctx, repo, currency, issueDate)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPENEquivalent(*rate, totals), nil
+}
+
+// fillExchangeRates completes the rate of the payment date of the documents in foreign
+// currency of a retention or perception that do not carry one.
+func fillExchangeRates(ctx context.Context, repo domain.ExchangeRateRepository, docs []domain.WithholdingDocument) error {
+	for i := range docs {
+		d := &docs[i]
+		if d.Currency == "" || d.Currency == domain.CurrencyPEN || !d.ExchangeRate.IsZero() || d.PaymentDate.IsZero() {
+			continue
+		}
+		if err := domain.ValidateCurrency(d.Currency); err != nil {
+			return fmt.Errorf("comprobante %s: %w", d.ReferenceID(), err)
+		}
+		rate, err := exchangeRate(ctx, repo, d.Currency, d.PaymentDate)
+		if err != nil {
+			return fmt.Errorf("comprobante %s: %w", d.ReferenceID(), err)
+		}
+		d.ExchangeRate = rate.Sell
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetInterestPolicy configures the late-payment interest charged by an issuer.
+func (s *InvoiceService) SetInterestPolicy(policy domain.InterestPolicy) error {
+	return s.interestPolicies.Set(policy)
+}
+
+// GenerateInterestDrafts computes, for every issuer with an interest policy, the interest of
+// the overdue installments of its credit invoices up to asOf and stores a debit note draft
+// per invoice. Invoices that already have a draft pending review are skipped.
+func (s *InvoiceService) GenerateInterestDrafts(asOf time.Time) ([]*domain.DebitNoteDraft, error) {
+	ctx := context.Background()
+	var generated []*domain.DebitNoteDraft
+	for _, policy := range s.interestPolicies.All() {
+		invoices, err := s.invoiceRepo.FindByIssueDate(ctx, policy.RUC, time.Time{}, asOf)
+		if err != nil {
+			return generated, fmt.Errorf("error al buscar las facturas del emisor %s: %w", policy.RUC, err)
+		}
+		for _, invoice := range invoices {
+			if invoice.CheckAffectable() != nil {
+				continue
+			}
+			draft, err := s.interestDraft(ctx, invoice, policy, asOf)
+			if err != nil {
+				return generated, err
+			}
+			if draft == nil {
+				continue
+			}
+			if err := s.draftRepo.Save(ctx, draft); err != nil {
+				return generated, fmt.Errorf("error al guardar el borrador de intereses: %w", err)
+			}
+			generated = append(generated, draft)
+		}
+	}
+	return generated, nil
+}
+
+// interestDraft builds the interest draft of the installments still owed on an invoice after
+// its credit notes, continuing from the last day charged by the debit notes issued from
+// previous drafts that reached SUNAT. The installments of discarded drafts are not charged again.
+func (s *InvoiceService) interestDraft(ctx context.Context, invoice *domain.Invoice, policy domain.InterestPolicy, asOf time.Time) (*domain.DebitNoteDraft, error) {
+	drafts, err := s.draftRepo.FindByInvoice(ctx, invoice.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar los borradores de la factura %s: %w", invoice.ID, err)
+	}
+	credits, err := s.noteRepo.FindCreditNotesByReference(ctx, invoice.Issuer.RUC, invoice.Series, invoice.Number)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar las notas de crédito: %w", err)
+	}
+	debits, err := s.noteRepo.FindDebitNotesByReference(ctx, invoice.Issuer.RUC, invoice.Series, invoice.Number)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar las notas de débito: %w", err)
+	}
+	issued := make(map[string]bool, len(debits))
+	for _, dn := range debits {
+		switch dn.Status {
+		case "ENVIADO", "ACEPTADO", "PENDIENTE_RESUMEN": // Sent, or queued in the daily summary
+			issued[dn.ID] = true
+		}
+	}
+
+	chargedUntil := make(map[int]time.Time)
+	stopped := make(map[int]bool)
+	for _, d := range drafts {
+		switch d.Status {
+		case "BORRADOR":
+			return nil, nil
+		case "DESCARTADO":
+			for _, item := range d.Items {
+				stopped[item.Installment] = true
+			}
+		case "EMITIDO":
+			if !issued[d.DebitNoteID] {
+				continue
+			}
+			for _, item := range d.Items {
+				if item.To.After(chargedUntil[item.Installment]) {
+					chargedUntil[item.Installment] = item.To
+				}
+			}
+		}
+	}
+
+	installments := domain.OutstandingInstallments(invoice, credits)
+	draft := domain.NewInterestDraft(invoice, installments, policy, chargedUntil, stopped, asOf)
+	if draft != nil {
+		draft.ID = uuid.New().String()
+	}
+	return draft, nil
+}
+
+// ListDebitNoteDrafts returns the debit note drafts of the issuer, optionally filtered by status.
+func (s *InvoiceService) ListDebitNoteDrafts(ruc, status string) ([]*domain.DebitNoteDraft, error) {
+	return s.draftRepo.FindByStatus(context.Background(), ruc, status)
+}
+
+// IssueDebitNoteDraft issues the debit note of a reviewed draft with the given series and number.
+func (s *InvoiceService) IssueDebitNoteDraft(id, series string, number int) (*domain.DebitNote, error) {
+	ctx := context.Background()
+	draft, err := s.draftRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != "BORRADOR" {
+		return nil, fmt.Errorf("el borrador %s ya fue %s", id, draft.Status)
+	}
+	if series == "" || number == 0 {
+		return nil, fmt.Errorf("serie y número son requeridos")
+	}
+
+	dn := draft.DebitNote
+	dn.Lines = append([]domain.InvoiceLine(nil), draft.DebitNote.Lines...)
+	dn.Series = series
+	dn.Number = number
+	dn.IssueDate = time.Time{}
+	issued, err := s.CreateDebitNote(&dn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.draftRepo.UpdateStatus(ctx, id, "EMITIDO", issued.ID); err != nil {
+		return nil, fmt.Errorf("error al actualizar el borrador %s: %w", id, err)
+	}
+	return issued, nil
+}
+
+// DiscardDebitNoteDraft discards a draft, e.g. because the installment was already paid.
+func (s *InvoiceService) DiscardDebitNoteDraft(id string) error {
+	ctx := context.Background()
+	draft, err := s.draftRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if draft.Status != "BORRADOR" {
+		return fmt.Errorf("el borrador %s ya fue %s", id, draft.Status)
+	}
+	return s.draftRepo.UpdateStatus(ctx, id, "DESCARTADO", "")
+}
+
+// StartInterestJob generates the interest drafts once a day until the context is cancelled.
+func (s *InvoiceService) StartInterestJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			drafts, err := s.GenerateInterestDrafts(now)
+			if err != nil {
+				log.Printf("Error al generar borradores de intereses: %v", err)
+				continue
+			}
+			log.Printf("Borradores de notas de débito por intereses generados: %d", len(drafts))
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"FacturacionSunat/internal/domain"
 	"FacturacionSunat/internal/platform/signer"
 	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/decimal"
 	"FacturacionSunat/pkg/ubl"
 	"context"
 	"encoding/xml"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,50 +17,151 @@ import (
 
 // InvoiceService is the service for handling invoice business logic.
 type InvoiceService struct {
-	invoiceRepo domain.InvoiceRepository
-	signer      *signer.XMLSigner
-	sunatClient *sunat.Client
+	invoiceRepo      domain.InvoiceRepository
+	noteRepo         domain.NoteRepository
+	summaryRepo      domain.SummaryRepository
+	draftRepo        domain.DebitNoteDraftRepository
+	contingencyRepo  domain.ContingencyRepository
+	exchangeRateRepo domain.ExchangeRateRepository
+	signer           *signer.XMLSigner
+	sunatClient      *sunat.Client
+	noteMu           sync.Mutex // Serializes the balance check and registration of notes
+	prepaidMu        sync.Mutex // Serializes the deduction of prepayment balances
+
+	interestPolicies *domain.InterestPolicyRegistry
+	contingencies    *domain.ContingencyRegistry
 }
 
 // NewInvoiceService creates a new InvoiceService.
-func NewInvoiceService(repo domain.InvoiceRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *InvoiceService {
+func NewInvoiceService(repo domain.InvoiceRepository, noteRepo domain.NoteRepository, summaryRepo domain.SummaryRepository, draftRepo domain.DebitNoteDraftRepository, contingencyRepo domain.ContingencyRepository, exchangeRateRepo domain.ExchangeRateRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *InvoiceService {
 	return &InvoiceService{
-	invoiceRepo: repo,
-	signer:      signer,
-	sunatClient: sunatClient,
+		invoiceRepo:      repo,
+		noteRepo:         noteRepo,
+		summaryRepo:      summaryRepo,
+		draftRepo:        draftRepo,
+		contingencyRepo:  contingencyRepo,
+		exchangeRateRepo: exchangeRateRepo,
+		signer:           signer,
+		sunatClient:      sunatClient,
+		interestPolicies: domain.NewInterestPolicyRegistry(),
+		contingencies:    domain.NewContingencyRegistry(),
 	}
 }
 
 // Create processes a new invoice.
 func (s *InvoiceService) Create(invoice *domain.Invoice) (*domain.Invoice, error) {
-	// 1. Basic validation.
-	if invoice.Series == "" || invoice.Number == 0 {
+	// 1. Basic validation. Documents issued in contingency are numbered once validated.
+	contingency, inContingency := s.contingencies.Lookup(invoice.Issuer.RUC)
+	if !inContingency && (invoice.Series == "" || invoice.Number == 0) {
 		return nil, fmt.Errorf("serie y número son requeridos")
 	}
 
 	// 2. Set server-side fields.
 	invoice.ID = uuid.New().String()
 	invoice.Status = "RECIBIDO"
-	invoice.IssueDate = time.Now()
-	if err := s.invoiceRepo.Save(context.Background(), invoice); err != nil {
-		return nil, fmt.Errorf("error al guardar la factura: %w", err)
+	if invoice.IssueDate.IsZero() {
+		invoice.IssueDate = time.Now()
+	}
+	if invoice.Currency == "" {
+		invoice.Currency = domain.CurrencyPEN
+	}
+	if err := domain.ValidateCurrency(invoice.Currency); err != nil {
+		return nil, err
+	}
+
+	if err := invoice.ValidateExport(); err != nil {
+		return nil, err
+	}
+	if err := invoice.ValidateShipment(); err != nil {
+		return nil, err
+	}
+
+	// 3. Resolve the prepayments to deduct and calculate the totals with the tax rate
+	// in force on the issue date, and in soles with the exchange rate of that date.
+	prepaidInvoices, err := s.resolvePrepayments(context.Background(), invoice)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := calculateTotals(invoice.Issuer, invoice.IssueDate, invoice.Lines, invoice.AllowanceCharges)
+	if err != nil {
+		return nil, err
+	}
+	var prepaid decimal.Decimal
+	for _, p := range invoice.Prepayments {
+		prepaid = prepaid.Add(p.Amount)
+	}
+	totals.ApplyPrepayments(prepaid)
+	invoice.Totals = totals
+	if invoice.PEN, err = penEquivalent(context.Background(), s.exchangeRateRepo, invoice.Currency, invoice.IssueDate, totals); err != nil {
+		return nil, err
+	}
+	if invoice.IsPrepayment {
+		invoice.PrepaidBalance = invoice.Totals.Total
+	}
+	if err := invoice.ApplyDetraction(); err != nil {
+		return nil, err
+	}
+	if err := invoice.ApplyPerception(); err != nil {
+		return nil, err
+	}
+	if err := invoice.ApplyPaymentTerms(); err != nil {
+		return nil, err
+	}
+	if err := invoice.ApplyLegends(); err != nil {
+		return nil, err
+	}
+
+	// Documents issued in contingency take the next number of the contingency series of
+	// their type, so that rejected documents do not use up numbers.
+	if inContingency {
+		if err := s.allocateContingencyNumber(context.Background(), invoice, contingency); err != nil {
+			return nil, err
+		}
+	}
+
+	// Deduct the prepayments and register the invoice under the lock, so that invoices
+	// issued at the same time cannot deduct the same prepayment balance. The balance is
+	// given back if the invoice cannot be issued.
+	s.prepaidMu.Lock()
+	err = s.consumePrepayments(context.Background(), invoice, prepaidInvoices)
+	if err == nil {
+		if err = s.invoiceRepo.Save(context.Background(), invoice); err != nil {
+			s.restorePrepayments(context.Background(), invoice, prepaidInvoices)
+			err = fmt.Errorf("error al guardar la factura: %w", err)
+		}
+	}
+	s.prepaidMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Contingency documents are reported later in the RF summary instead of being sent.
+	if inContingency {
+		if err := s.queueContingencyDocument(context.Background(), invoice, contingency); err != nil {
+			s.releasePrepayments(invoice, prepaidInvoices)
+			return nil, err
+		}
+		return invoice, nil
 	}
 
 	// 4. Build the UBL structure.
 	ublInvoice, err := ubl.BuildInvoice(invoice)
 	if err != nil {
+		s.releasePrepayments(invoice, prepaidInvoices)
 		return nil, fmt.Errorf("error al construir el UBL: %w", err)
 	}
 
 	// 5. Generate the unsigned XML.
 	unsignedXML, err := xml.MarshalIndent(ublInvoice, "", "  ")
 	if err != nil {
+		s.releasePrepayments(invoice, prepaidInvoices)
 		return nil, fmt.Errorf("error al generar el XML: %w", err)
 	}
 
 	// 6. Sign the XML.
 	signedXML, err := s.signer.Sign(unsignedXML)
 	if err != nil {
+		s.releasePrepayments(invoice, prepaidInvoices)
 		return nil, fmt.Errorf("error al firmar el XML: %w", err)
 	}
 	invoice.Status = "FIRMADO"
@@ -69,6 +172,7 @@ func (s *InvoiceService) Create(invoice *domain.Invoice) (*domain.Invoice, error
 	if err != nil {
 		// In a real app, you would handle specific SUNAT errors here.
 		invoice.Status = "RECHAZADO"
+		s.releasePrepayments(invoice, prepaidInvoices)
 		return nil, fmt.Errorf("error al enviar a SUNAT: %w", err)
 	}
 
@@ -87,74 +191,220 @@ func (s *InvoiceService) Create(invoice *domain.Invoice) (*domain.Invoice, error
 func (s *InvoiceService) CreateCreditNote(cn *domain.CreditNote) (*domain.CreditNote, error) {
 	cn.ID = uuid.New().String()
 	cn.Status = "RECIBIDO"
-	cn.IssueDate = time.Now()
+	if cn.IssueDate.IsZero() {
+		cn.IssueDate = time.Now()
+	}
+
+	// Resolve the affected invoice and apply the catalog 09 rules of the reason
+	original, err := s.findReferencedInvoice(context.Background(), cn.Issuer.RUC, cn.DiscrepancyResponse.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckNoteSeries(cn.Series, original); err != nil {
+		return nil, err
+	}
+	if cn.Currency == "" {
+		cn.Currency = original.Currency
+	}
+	cn.DiscrepancyResponse.IssueDate = original.IssueDate
+	if err := cn.PrepareLines(original); err != nil {
+		return nil, err
+	}
+
+	totals, err := calculateTotals(cn.Issuer, cn.DiscrepancyResponse.TaxDate(cn.IssueDate), cn.Lines, cn.AllowanceCharges)
+	if err != nil {
+		return nil, err
+	}
+	var prepaid decimal.Decimal
+	for _, p := range cn.Prepayments {
+		prepaid = prepaid.Add(p.Amount)
+	}
+	totals.ApplyPrepayments(prepaid)
+	cn.Totals = totals
+	if err := cn.ValidateAmounts(original); err != nil {
+		return nil, err
+	}
+	if cn.PEN, err = s.notePENEquivalent(context.Background(), original, totals); err != nil {
+		return nil, err
+	}
+	if err := cn.ApplyLegends(); err != nil {
+		return nil, err
+	}
+
+	// Check the remaining balance and register the note before sending it, so that
+	// notes processed at the same time cannot credit more than the invoice total.
+	s.noteMu.Lock()
+	balance, err := s.documentBalance(context.Background(), original)
+	if err == nil {
+		err = balance.CheckCredit(cn)
+	}
+	if err == nil {
+		err = s.noteRepo.SaveCreditNote(context.Background(), cn)
+	}
+	s.noteMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
 
+	// From here on a failure rejects the registered note, releasing its amount from the
+	// balance of the invoice.
 	ublCreditNote, err := ubl.BuildCreditNote(cn)
 	if err != nil {
+		s.rejectCreditNote(cn)
 		return nil, fmt.Errorf("error al construir UBL de nota de crédito: %w", err)
 	}
 
 	unsignedXML, err := xml.MarshalIndent(ublCreditNote, "", "  ")
 	if err != nil {
+		s.rejectCreditNote(cn)
 		return nil, fmt.Errorf("error al generar XML de nota de crédito: %w", err)
 	}
 
 	signedXML, err := s.signer.Sign(unsignedXML)
 	if err != nil {
+		s.rejectCreditNote(cn)
 		return nil, fmt.Errorf("error al firmar XML de nota de crédito: %w", err)
 	}
 	cn.Status = "FIRMADO"
 
+	// Notes of boletas are reported in the daily summary instead of sendBill.
+	if domain.ReportsInSummary(cn.DiscrepancyResponse.DocType) {
+		if err := s.summaryRepo.AddPendingLine(context.Background(), cn.Issuer.RUC, domain.SummaryLineFromCreditNote(cn)); err != nil {
+			s.rejectCreditNote(cn)
+			return nil, fmt.Errorf("error al encolar la nota de crédito para el resumen diario: %w", err)
+		}
+		if err := s.noteRepo.UpdateCreditNoteStatus(context.Background(), cn.ID, "PENDIENTE_RESUMEN", ""); err != nil {
+			return nil, err
+		}
+		cn.Status = "PENDIENTE_RESUMEN"
+		return cn, nil
+	}
+
 	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", cn.Issuer.RUC, cn.Type, cn.Series, cn.Number)
 	ticket, err := s.sunatClient.SendBill(fileName, signedXML)
 	if err != nil {
-		cn.Status = "RECHAZADO"
+		s.rejectCreditNote(cn)
 		return nil, fmt.Errorf("error al enviar nota de crédito a SUNAT: %w", err)
 	}
-
-	cn.TicketID = ticket // Assuming domain.CreditNote has a TicketID field
 	fmt.Printf("Ticket de Nota de Crédito recibido de SUNAT: %s\n", ticket)
 
+	if err := s.noteRepo.UpdateCreditNoteStatus(context.Background(), cn.ID, "ENVIADO", ticket); err != nil {
+		return nil, err
+	}
+	cn.TicketID = ticket
 	cn.Status = "ENVIADO"
 	return cn, nil
 }
 
+// rejectCreditNote marks a registered credit note as rejected when it could not be issued.
+func (s *InvoiceService) rejectCreditNote(cn *domain.CreditNote) {
+	cn.Status = "RECHAZADO"
+	if err := s.noteRepo.UpdateCreditNoteStatus(context.Background(), cn.ID, cn.Status, ""); err != nil {
+		fmt.Printf("No se pudo actualizar el estado de la nota de crédito %s: %v\n", cn.ID, err)
+	}
+}
+
 // CreateDebitNote processes a new debit note.
 func (s *InvoiceService) CreateDebitNote(dn *domain.DebitNote) (*domain.DebitNote, error) {
 	dn.ID = uuid.New().String()
 	dn.Status = "RECIBIDO"
-	dn.IssueDate = time.Now()
+	if dn.IssueDate.IsZero() {
+		dn.IssueDate = time.Now()
+	}
+
+	original, err := s.findReferencedInvoice(context.Background(), dn.Issuer.RUC, dn.DiscrepancyResponse.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := original.CheckAffectable(); err != nil {
+		return nil, err
+	}
+	if err := domain.CheckNoteSeries(dn.Series, original); err != nil {
+		return nil, err
+	}
+	if dn.Currency == "" {
+		dn.Currency = original.Currency
+	}
+	if dn.Currency != original.Currency {
+		return nil, fmt.Errorf("la moneda de la nota de débito debe ser la del comprobante afectado (%s)", original.Currency)
+	}
+	dn.DiscrepancyResponse.DocType = original.Type
+	dn.DiscrepancyResponse.IssueDate = original.IssueDate
+
+	totals, err := calculateTotals(dn.Issuer, dn.DiscrepancyResponse.TaxDate(dn.IssueDate), dn.Lines, nil)
+	if err != nil {
+		return nil, err
+	}
+	dn.Totals = totals
+	if dn.PEN, err = s.notePENEquivalent(context.Background(), original, totals); err != nil {
+		return nil, err
+	}
+	if err := dn.ApplyLegends(); err != nil {
+		return nil, err
+	}
 
+	if err := s.noteRepo.SaveDebitNote(context.Background(), dn); err != nil {
+		return nil, fmt.Errorf("error al guardar la nota de débito: %w", err)
+	}
+
+	// From here on a failure rejects the saved note, so that it does not count in the
+	// balance of the invoice.
 	ublDebitNote, err := ubl.BuildDebitNote(dn)
 	if err != nil {
+		s.rejectDebitNote(dn)
 		return nil, fmt.Errorf("error al construir UBL de nota de débito: %w", err)
 	}
 
 	unsignedXML, err := xml.MarshalIndent(ublDebitNote, "", "  ")
 	if err != nil {
+		s.rejectDebitNote(dn)
 		return nil, fmt.Errorf("error al generar XML de nota de débito: %w", err)
 	}
 
 	signedXML, err := s.signer.Sign(unsignedXML)
 	if err != nil {
+		s.rejectDebitNote(dn)
 		return nil, fmt.Errorf("error al firmar XML de nota de débito: %w", err)
 	}
 	dn.Status = "FIRMADO"
 
+	// Notes of boletas are reported in the daily summary instead of sendBill.
+	if domain.ReportsInSummary(dn.DiscrepancyResponse.DocType) {
+		if err := s.summaryRepo.AddPendingLine(context.Background(), dn.Issuer.RUC, domain.SummaryLineFromDebitNote(dn)); err != nil {
+			s.rejectDebitNote(dn)
+			return nil, fmt.Errorf("error al encolar la nota de débito para el resumen diario: %w", err)
+		}
+		if err := s.noteRepo.UpdateDebitNoteStatus(context.Background(), dn.ID, "PENDIENTE_RESUMEN", ""); err != nil {
+			return nil, err
+		}
+		dn.Status = "PENDIENTE_RESUMEN"
+		return dn, nil
+	}
+
 	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", dn.Issuer.RUC, dn.Type, dn.Series, dn.Number)
 	ticket, err := s.sunatClient.SendBill(fileName, signedXML)
 	if err != nil {
-		dn.Status = "RECHAZADO"
+		s.rejectDebitNote(dn)
 		return nil, fmt.Errorf("error al enviar nota de débito a SUNAT: %w", err)
 	}
-
-	dn.TicketID = ticket // Assuming domain.DebitNote has a TicketID field
 	fmt.Printf("Ticket de Nota de Débito recibido de SUNAT: %s\n", ticket)
 
+	if err := s.noteRepo.UpdateDebitNoteStatus(context.Background(), dn.ID, "ENVIADO", ticket); err != nil {
+		return nil, err
+	}
+	dn.TicketID = ticket
 	dn.Status = "ENVIADO"
 	return dn, nil
 }
 
+// rejectDebitNote marks a saved debit note as rejected when it could not be issued.
+func (s *InvoiceService) rejectDebitNote(dn *domain.DebitNote) {
+	dn.Status = "RECHAZADO"
+	if err := s.noteRepo.UpdateDebitNoteStatus(context.Background(), dn.ID, dn.Status, ""); err != nil {
+		fmt.Printf("No se pudo actualizar el estado de la nota de débito %s: %v\n", dn.ID, err)
+	}
+}
+
 // GetDocumentStatus retrieves the status of a document from SUNAT.
 func (s *InvoiceService) GetDocumentStatus(id string) (string, error) {
 	statusResp, err := s.sunatClient.GetStatus(id)
@@ -174,3 +424,263 @@ func (s *InvoiceService) GetDocumentStatusCdr(ruc, docType, series, number strin
 	// Here you would parse statusCdrResp.Content (Base64 encoded CDR zip) if needed.
 	return statusCdrResp, nil
 }
+
+// SendDailySummary reports the boleta notes of the issuer issued on the reference date in a
+// daily summary (RC) and returns it with the SUNAT ticket to check its status.
+func (s *InvoiceService) SendDailySummary(issuer domain.Issuer, referenceDate time.Time) (*domain.DailySummary, error) {
+	ctx := context.Background()
+	lines, err := s.summaryRepo.FindPendingLines(ctx, issuer.RUC, referenceDate)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar comprobantes pendientes de resumen: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no hay comprobantes pendientes de resumen del %s", referenceDate.Format("2006-01-02"))
+	}
+
+	now := time.Now()
+	count, err := s.summaryRepo.CountByIssueDate(ctx, issuer.RUC, now)
+	if err != nil {
+		return nil, fmt.Errorf("error al numerar el resumen diario: %w", err)
+	}
+	summary := &domain.DailySummary{
+		ID:            fmt.Sprintf("RC-%s-%d", now.Format("20060102"), count+1),
+		Issuer:        issuer,
+		ReferenceDate: referenceDate,
+		IssueDate:     now,
+		Lines:         lines,
+		Status:        "RECIBIDO",
+	}
+
+	ublSummary, err := ubl.BuildDailySummary(summary)
+	if err != nil {
+		return nil, fmt.Errorf("error al construir UBL del resumen diario: %w", err)
+	}
+	unsignedXML, err := xml.MarshalIndent(ublSummary, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error al generar XML del resumen diario: %w", err)
+	}
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		return nil, fmt.Errorf("error al firmar XML del resumen diario: %w", err)
+	}
+	summary.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s.xml", issuer.RUC, summary.ID)
+	ticket, err := s.sunatClient.SendSummary(fileName, signedXML)
+	if err != nil {
+		return nil, fmt.Errorf("error al enviar el resumen diario a SUNAT: %w", err)
+	}
+	summary.TicketID = ticket
+	summary.Status = "ENVIADO"
+
+	if err := s.summaryRepo.Save(ctx, summary); err != nil {
+		return nil, fmt.Errorf("error al guardar el resumen diario: %w", err)
+	}
+	return summary, nil
+}
+
+// DocumentBalance returns the net balance of an invoice after its credit and debit notes.
+func (s *InvoiceService) DocumentBalance(id string) (*domain.DocumentBalance, error) {
+	invoice, err := s.invoiceRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar la factura: %w", err)
+	}
+	return s.documentBalance(context.Background(), invoice)
+}
+
+// PerceptionReport lists the perceptions charged by the issuer on invoices issued between from and to.
+func (s *InvoiceService) PerceptionReport(ruc string, from, to time.Time) ([]domain.PerceptionReportLine, error) {
+	invoices, err := s.invoiceRepo.FindByIssueDate(context.Background(), ruc, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar facturas: %w", err)
+	}
+
+	report := []domain.PerceptionReportLine{}
+	for _, inv := range invoices {
+		if inv.Perception == nil {
+			continue
+		}
+		line := domain.PerceptionReportLine{
+			InvoiceID:           inv.ID,
+			DocType:             inv.Type,
+			DocumentNumber:      fmt.Sprintf("%s-%d", inv.Series, inv.Number),
+			IssueDate:           inv.IssueDate,
+			RecipientDocNum:     inv.Recipient.DocNum,
+			RecipientName:       inv.Recipient.Name,
+			Currency:            inv.Currency,
+			Total:               inv.Totals.Total,
+			Code:                inv.Perception.Code,
+			Percent:             inv.Perception.Percent,
+			BaseAmount:          inv.Perception.BaseAmount,
+			Amount:              inv.Perception.Amount,
+			TotalWithPerception: inv.Perception.TotalWithPerception,
+			Status:              inv.Status,
+		}
+		if inv.PEN != nil {
+			line.ExchangeRate = inv.PEN.ExchangeRate
+		}
+		report = append(report, line)
+	}
+	return report, nil
+}
+
+// notePENEquivalent returns the totals of a note in soles at the exchange rate of the invoice
+// it modifies, nil for notes in PEN. Invoices issued before the rates were recorded use the
+// rate of their issue date.
+func (s *InvoiceService) notePENEquivalent(ctx context.Context, original *domain.Invoice, totals domain.Totals) (*domain.PENEquivalent, error) {
+	if original.PEN != nil {
+		return original.PEN.ForTotals(totals), nil
+	}
+	return penEquivalent(ctx, s.exchangeRateRepo, original.Currency, original.IssueDate, totals)
+}
+
+// resolvePrepayments looks up the prepayment invoices deducted by the invoice, validates
+// their remaining balance and adds the catalog 53 allowance (code 04) that reduces the taxable base.
+// It returns the prepayment invoices in the same order as invoice.Prepayments.
+func (s *InvoiceService) resolvePrepayments(ctx context.Context, invoice *domain.Invoice) ([]*domain.Invoice, error) {
+	if len(invoice.Prepayments) == 0 {
+		return nil, nil
+	}
+	if invoice.IsPrepayment {
+		return nil, fmt.Errorf("una factura de anticipo no puede deducir otros anticipos")
+	}
+
+	originals := make([]*domain.Invoice, 0, len(invoice.Prepayments))
+	seen := make(map[string]bool, len(invoice.Prepayments))
+	var taxable decimal.Decimal
+	for i := range invoice.Prepayments {
+		p := &invoice.Prepayments[i]
+		if seen[p.ReferenceID] {
+			return nil, fmt.Errorf("el anticipo %s se deduce más de una vez", p.ReferenceID)
+		}
+		seen[p.ReferenceID] = true
+		original, err := s.findReferencedInvoice(ctx, invoice.Issuer.RUC, p.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if !original.IsPrepayment {
+			return nil, fmt.Errorf("el comprobante %s no es una factura de anticipo", p.ReferenceID)
+		}
+		if original.Status == "RECHAZADO" {
+			return nil, fmt.Errorf("el anticipo %s fue rechazado por SUNAT", p.ReferenceID)
+		}
+		if original.Currency != invoice.Currency {
+			return nil, fmt.Errorf("el anticipo %s está en %s y la factura en %s", p.ReferenceID, original.Currency, invoice.Currency)
+		}
+		if p.Amount.IsZero() {
+			p.Amount = original.PrepaidBalance
+		}
+		if !p.Amount.IsPositive() || p.Amount.GreaterThan(original.PrepaidBalance) {
+			return nil, fmt.Errorf("el monto a deducir del anticipo %s excede su saldo de %s", p.ReferenceID, original.PrepaidBalance)
+		}
+
+		p.DocType = domain.PrepaymentInvoiceDocType
+		if original.Type == "03" {
+			p.DocType = domain.PrepaymentReceiptDocType
+		}
+		p.TaxableAmount = p.Amount
+		if original.Totals.Total.IsPositive() {
+			p.TaxableAmount = domain.Round2(p.Amount.Mul(original.Totals.Taxable).Div(original.Totals.Total))
+		}
+		taxable = taxable.Add(p.TaxableAmount)
+		originals = append(originals, original)
+	}
+
+	taxable = domain.Round2(taxable)
+	invoice.AllowanceCharges = append(invoice.AllowanceCharges, domain.AllowanceCharge{
+		ReasonCode: "04",
+		Amount:     taxable,
+		BaseAmount: taxable,
+	})
+	return originals, nil
+}
+
+// consumePrepayments subtracts the amounts deducted by the invoice from the balance of its
+// prepayment invoices, given in the same order as invoice.Prepayments. The balance is read
+// again and checked because another invoice may have deducted it since it was resolved.
+// The caller must hold prepaidMu; on error no balance is left changed.
+func (s *InvoiceService) consumePrepayments(ctx context.Context, invoice *domain.Invoice, prepaidInvoices []*domain.Invoice) error {
+	for i, original := range prepaidInvoices {
+		p := invoice.Prepayments[i]
+		current, err := s.invoiceRepo.FindByID(ctx, original.ID)
+		if err != nil {
+			s.restorePrepayments(ctx, invoice, prepaidInvoices[:i])
+			return fmt.Errorf("error al buscar el anticipo %s: %w", p.ReferenceID, err)
+		}
+		if p.Amount.GreaterThan(current.PrepaidBalance) {
+			s.restorePrepayments(ctx, invoice, prepaidInvoices[:i])
+			return fmt.Errorf("el monto a deducir del anticipo %s excede su saldo de %s", p.ReferenceID, current.PrepaidBalance)
+		}
+		if err := s.invoiceRepo.UpdatePrepaidBalance(ctx, current.ID, domain.Round2(current.PrepaidBalance.Sub(p.Amount))); err != nil {
+			s.restorePrepayments(ctx, invoice, prepaidInvoices[:i])
+			return fmt.Errorf("error al actualizar el saldo del anticipo %s: %w", p.ReferenceID, err)
+		}
+	}
+	return nil
+}
+
+// restorePrepayments gives back the amounts deducted by the invoice to its prepayment
+// invoices. The caller must hold prepaidMu.
+func (s *InvoiceService) restorePrepayments(ctx context.Context, invoice *domain.Invoice, prepaidInvoices []*domain.Invoice) {
+	for i, original := range prepaidInvoices {
+		p := invoice.Prepayments[i]
+		current, err := s.invoiceRepo.FindByID(ctx, original.ID)
+		if err == nil {
+			err = s.invoiceRepo.UpdatePrepaidBalance(ctx, current.ID, domain.Round2(current.PrepaidBalance.Add(p.Amount)))
+		}
+		if err != nil {
+			fmt.Printf("No se pudo restituir el saldo del anticipo %s: %v\n", p.ReferenceID, err)
+		}
+	}
+}
+
+// releasePrepayments gives back the prepayment balance deducted by an invoice that could
+// not be issued.
+func (s *InvoiceService) releasePrepayments(invoice *domain.Invoice, prepaidInvoices []*domain.Invoice) {
+	if len(prepaidInvoices) == 0 {
+		return
+	}
+	s.prepaidMu.Lock()
+	defer s.prepaidMu.Unlock()
+	s.restorePrepayments(context.Background(), invoice, prepaidInvoices)
+}
+
+// documentBalance loads the notes that reference the invoice and computes its balance.
+func (s *InvoiceService) documentBalance(ctx context.Context, invoice *domain.Invoice) (*domain.DocumentBalance, error) {
+	credits, err := s.noteRepo.FindCreditNotesByReference(ctx, invoice.Issuer.RUC, invoice.Series, invoice.Number)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar las notas de crédito: %w", err)
+	}
+	debits, err := s.noteRepo.FindDebitNotesByReference(ctx, invoice.Issuer.RUC, invoice.Series, invoice.Number)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar las notas de débito: %w", err)
+	}
+	return domain.NewDocumentBalance(invoice, credits, debits), nil
+}
+
+// findReferencedInvoice looks up an invoice of the issuer by a SERIE-NUMERO reference.
+func (s *InvoiceService) findReferencedInvoice(ctx context.Context, ruc, ref string) (*domain.Invoice, error) {
+	series, number, err := domain.ParseDocumentReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	original, err := s.invoiceRepo.FindByNumber(ctx, ruc, series, number)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar el comprobante %s: %w", ref, err)
+	}
+	return original, nil
+}
+
+// calculateTotals computes the line taxes and document totals using the tax rates
+// in force for the issuer regime on the issue date.
+func calculateTotals(issuer domain.Issuer, issueDate time.Time, lines []domain.InvoiceLine, allowanceCharges []domain.AllowanceCharge) (domain.Totals, error) {
+	percents, err := domain.DefaultTaxRates.Percents(issuer.Regime, issueDate)
+	if err != nil {
+		return domain.Totals{}, fmt.Errorf("error al obtener la tasa de IGV: %w", err)
+	}
+	totals, err := domain.CalculateTotals(lines, allowanceCharges, percents)
+	if err != nil {
+		return domain.Totals{}, fmt.Errorf("error al calcular los totales: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/internal/platform/signer"
+	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/ubl"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PerceptionService is the service for handling perception receipts.
+type PerceptionService struct {
+	perceptionRepo   domain.PerceptionReceiptRepository
+	exchangeRateRepo domain.ExchangeRateRepository
+	signer           *signer.XMLSigner
+	sunatClient      *sunat.Client // Bill service of retentions and perceptions
+}
+
+// NewPerceptionService creates a new PerceptionService.
+func NewPerceptionService(repo domain.PerceptionReceiptRepository, exchangeRateRepo domain.ExchangeRateRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *PerceptionService {
+	return &PerceptionService{
+		perceptionRepo:   repo,
+		exchangeRateRepo: exchangeRateRepo,
+		signer:           signer,
+		sunatClient:      sunatClient,
+	}
+}
+
+// Create calculates, signs and sends a new perception receipt to SUNAT.
+func (s *PerceptionService) Create(receipt *domain.PerceptionReceipt) (*domain.PerceptionReceipt, error) {
+	receipt.ID = uuid.New().String()
+	receipt.Status = "RECIBIDO"
+	if receipt.IssueDate.IsZero() {
+		receipt.IssueDate = time.Now()
+	}
+	if err := fillExchangeRates(context.Background(), s.exchangeRateRepo, receipt.Documents); err != nil {
+		return nil, err
+	}
+	if err := receipt.Calculate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.perceptionRepo.Save(context.Background(), receipt); err != nil {
+		return nil, fmt.Errorf("error al guardar la percepción: %w", err)
+	}
+
+	ublPerception, err := ubl.BuildPerception(receipt)
+	if err != nil {
+		s.reject(receipt, err)
+		return nil, fmt.Errorf("error al construir UBL de percepción: %w", err)
+	}
+
+	unsignedXML, err := xml.MarshalIndent(ublPerception, "", "  ")
+	if err != nil {
+		s.reject(receipt, err)
+		return nil, fmt.Errorf("error al generar XML de percepción: %w", err)
+	}
+
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		s.reject(receipt, err)
+		return nil, fmt.Errorf("error al firmar XML de percepción: %w", err)
+	}
+	receipt.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", receipt.Issuer.RUC, domain.DocTypePerceptionReceipt, receipt.Series, receipt.Number)
+	ticket, err := s.sunatClient.SendBill(fileName, signedXML)
+	if err != nil {
+		s.reject(receipt, err)
+		return nil, fmt.Errorf("error al enviar percepción a SUNAT: %w", err)
+	}
+
+	if err := s.perceptionRepo.UpdateStatus(context.Background(), receipt.ID, "ENVIADO", ticket, ""); err != nil {
+		return nil, err
+	}
+	receipt.TicketID = ticket
+	receipt.Status = "ENVIADO"
+	return receipt, nil
+}
+
+// reject marks a saved perception receipt as rejected, with the cause, when it could not be issued.
+func (s *PerceptionService) reject(receipt *domain.PerceptionReceipt, cause error) {
+	receipt.Status = "RECHAZADO"
+	if err := s.perceptionRepo.UpdateStatus(context.Background(), receipt.ID, receipt.Status, "", cause.Error()); err != nil {
+		fmt.Printf("No se pudo actualizar el estado de la percepción %s: %v\n", receipt.ID, err)
+	}
+}
+
+// GetStatus queries the ticket of a sent perception receipt and records the result: ACEPTADO
+// when SUNAT accepted it, RECHAZADO with the SUNAT message, or ENVIADO while it is in progress.
+func (s *PerceptionService) GetStatus(id string) (*domain.PerceptionReceipt, error) {
+	receipt, err := s.perceptionRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.TicketID == "" {
+		return nil, fmt.Errorf("la percepción %s-%d no tiene ticket de SUNAT", receipt.Series, receipt.Number)
+	}
+
+	status, err := s.sunatClient.GetStatus(receipt.TicketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar estado en SUNAT: %w", err)
+	}
+	switch status.StatusCode {
+	case "0": // Processed
+		receipt.Status = "ACEPTADO"
+	case "98": // In progress
+		return receipt, nil
+	case "99": // Processed with errors
+		receipt.Status = "RECHAZADO"
+	default:
+		return nil, fmt.Errorf("código de respuesta %q desconocido para el ticket %s", status.StatusCode, receipt.TicketID)
+	}
+
+	if err := s.perceptionRepo.UpdateStatus(context.Background(), receipt.ID, receipt.Status, receipt.TicketID, status.StatusMessage); err != nil {
+		return nil, err
+	}
+	receipt.StatusMessage = status.StatusMessage
+	return receipt, nil
+}
+
+// List returns the perception receipts of an issuer issued between from and to.
+func (s *PerceptionService) List(ruc string, from, to time.Time) ([]*domain.PerceptionReceipt, error) {
+	return s.perceptionRepo.FindByIssuer(context.Background(), ruc, from, to)
+}
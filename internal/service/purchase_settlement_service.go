@@ -0,0 +1,111 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/internal/platform/signer"
+	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/ubl"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PurchaseSettlementService is the service for handling purchase settlements.
+type PurchaseSettlementService struct {
+	settlementRepo   domain.PurchaseSettlementRepository
+	exchangeRateRepo domain.ExchangeRateRepository
+	signer           *signer.XMLSigner
+	sunatClient      *sunat.Client
+}
+
+// NewPurchaseSettlementService creates a new PurchaseSettlementService.
+func NewPurchaseSettlementService(repo domain.PurchaseSettlementRepository, exchangeRateRepo domain.ExchangeRateRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *PurchaseSettlementService {
+	return &PurchaseSettlementService{
+		settlementRepo:   repo,
+		exchangeRateRepo: exchangeRateRepo,
+		signer:           signer,
+		sunatClient:      sunatClient,
+	}
+}
+
+// Create calculates the totals and retentions of a new purchase settlement, then signs and
+// sends it to SUNAT like an invoice.
+func (s *PurchaseSettlementService) Create(settlement *domain.PurchaseSettlement) (*domain.PurchaseSettlement, error) {
+	settlement.ID = uuid.New().String()
+	settlement.Status = "RECIBIDO"
+	if settlement.IssueDate.IsZero() {
+		settlement.IssueDate = time.Now()
+	}
+	if settlement.Currency == "" {
+		settlement.Currency = domain.CurrencyPEN
+	}
+	if err := settlement.Validate(); err != nil {
+		return nil, err
+	}
+
+	totals, err := calculateTotals(settlement.Issuer, settlement.IssueDate, settlement.Lines, nil)
+	if err != nil {
+		return nil, err
+	}
+	settlement.Totals = totals
+	if settlement.PEN, err = penEquivalent(context.Background(), s.exchangeRateRepo, settlement.Currency, settlement.IssueDate, totals); err != nil {
+		return nil, err
+	}
+	settlement.ApplyRetentions()
+	if err := settlement.ApplyLegends(); err != nil {
+		return nil, err
+	}
+
+	if err := s.settlementRepo.Save(context.Background(), settlement); err != nil {
+		return nil, fmt.Errorf("error al guardar la liquidación de compra: %w", err)
+	}
+
+	ublInvoice, err := ubl.BuildPurchaseSettlement(settlement)
+	if err != nil {
+		s.reject(settlement)
+		return nil, fmt.Errorf("error al construir UBL de liquidación de compra: %w", err)
+	}
+
+	unsignedXML, err := xml.MarshalIndent(ublInvoice, "", "  ")
+	if err != nil {
+		s.reject(settlement)
+		return nil, fmt.Errorf("error al generar XML de liquidación de compra: %w", err)
+	}
+
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		s.reject(settlement)
+		return nil, fmt.Errorf("error al firmar XML de liquidación de compra: %w", err)
+	}
+	settlement.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", settlement.Issuer.RUC, domain.DocTypePurchaseSettlement, settlement.Series, settlement.Number)
+	ticket, err := s.sunatClient.SendBill(fileName, signedXML)
+	if err != nil {
+		s.reject(settlement)
+		return nil, fmt.Errorf("error al enviar liquidación de compra a SUNAT: %w", err)
+	}
+
+	if err := s.settlementRepo.UpdateStatus(context.Background(), settlement.ID, "ENVIADO", ticket); err != nil {
+		return nil, err
+	}
+	settlement.TicketID = ticket
+	settlement.Status = "ENVIADO"
+	return settlement, nil
+}
+
+// reject marks a saved purchase settlement as rejected when it could not be sent.
+func (s *PurchaseSettlementService) reject(settlement *domain.PurchaseSettlement) {
+	settlement.Status = "RECHAZADO"
+	if err := s.settlementRepo.UpdateStatus(context.Background(), settlement.ID, settlement.Status, ""); err != nil {
+		fmt.Printf("No se pudo actualizar el estado de la liquidación de compra %s: %v\n", settlement.ID, err)
+	}
+}
+
+// GetByID returns a stored purchase settlement.
+func (s *PurchaseSettlementService) GetByID(id string) (*domain.PurchaseSettlement, error) {
+	return s.settlementRepo.FindByID(context.Background(), id)
+}
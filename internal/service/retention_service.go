@@ -0,0 +1,97 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/internal/platform/signer"
+	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/ubl"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RetentionService is the service for handling retention certificates.
+type RetentionService struct {
+	retentionRepo    domain.RetentionRepository
+	exchangeRateRepo domain.ExchangeRateRepository
+	signer           *signer.XMLSigner
+	sunatClient      *sunat.Client // Bill service of retentions and perceptions
+}
+
+// NewRetentionService creates a new RetentionService.
+func NewRetentionService(repo domain.RetentionRepository, exchangeRateRepo domain.ExchangeRateRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *RetentionService {
+	return &RetentionService{
+		retentionRepo:    repo,
+		exchangeRateRepo: exchangeRateRepo,
+		signer:           signer,
+		sunatClient:      sunatClient,
+	}
+}
+
+// Create calculates, signs and sends a new retention certificate to SUNAT.
+func (s *RetentionService) Create(retention *domain.Retention) (*domain.Retention, error) {
+	retention.ID = uuid.New().String()
+	retention.Status = "RECIBIDO"
+	if retention.IssueDate.IsZero() {
+		retention.IssueDate = time.Now()
+	}
+	if err := fillExchangeRates(context.Background(), s.exchangeRateRepo, retention.Documents); err != nil {
+		return nil, err
+	}
+	if err := retention.Calculate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.retentionRepo.Save(context.Background(), retention); err != nil {
+		return nil, fmt.Errorf("error al guardar la retención: %w", err)
+	}
+
+	ublRetention, err := ubl.BuildRetention(retention)
+	if err != nil {
+		s.reject(retention)
+		return nil, fmt.Errorf("error al construir UBL de retención: %w", err)
+	}
+
+	unsignedXML, err := xml.MarshalIndent(ublRetention, "", "  ")
+	if err != nil {
+		s.reject(retention)
+		return nil, fmt.Errorf("error al generar XML de retención: %w", err)
+	}
+
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		s.reject(retention)
+		return nil, fmt.Errorf("error al firmar XML de retención: %w", err)
+	}
+	retention.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s-%s-%d.xml", retention.Issuer.RUC, domain.DocTypeRetention, retention.Series, retention.Number)
+	ticket, err := s.sunatClient.SendBill(fileName, signedXML)
+	if err != nil {
+		s.reject(retention)
+		return nil, fmt.Errorf("error al enviar retención a SUNAT: %w", err)
+	}
+
+	if err := s.retentionRepo.UpdateStatus(context.Background(), retention.ID, "ENVIADO", ticket); err != nil {
+		return nil, err
+	}
+	retention.TicketID = ticket
+	retention.Status = "ENVIADO"
+	return retention, nil
+}
+
+// reject marks a saved retention certificate as rejected when it could not be issued.
+func (s *RetentionService) reject(retention *domain.Retention) {
+	retention.Status = "RECHAZADO"
+	if err := s.retentionRepo.UpdateStatus(context.Background(), retention.ID, retention.Status, ""); err != nil {
+		fmt.Printf("No se pudo actualizar el estado de la retención %s: %v\n", retention.ID, err)
+	}
+}
+
+// GetByID returns a stored retention certificate.
+func (s *RetentionService) GetByID(id string) (*domain.Retention, error) {
+	return s.retentionRepo.FindByID(context.Background(), id)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/internal/platform/signer"
+	"FacturacionSunat/internal/platform/sunat"
+	"FacturacionSunat/pkg/ubl"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// ReversalService is the service for reverting retention and perception certificates
+// through reversal summaries (RR).
+type ReversalService struct {
+	reversalRepo   domain.ReversalSummaryRepository
+	retentionRepo  domain.RetentionRepository
+	perceptionRepo domain.PerceptionReceiptRepository
+	signer         *signer.XMLSigner
+	sunatClient    *sunat.Client // Bill service of retentions and perceptions
+}
+
+// NewReversalService creates a new ReversalService.
+func NewReversalService(reversalRepo domain.ReversalSummaryRepository, retentionRepo domain.RetentionRepository, perceptionRepo domain.PerceptionReceiptRepository, signer *signer.XMLSigner, sunatClient *sunat.Client) *ReversalService {
+	return &ReversalService{
+		reversalRepo:   reversalRepo,
+		retentionRepo:  retentionRepo,
+		perceptionRepo: perceptionRepo,
+		signer:         signer,
+		sunatClient:    sunatClient,
+	}
+}
+
+// RevertRetention sends a reversal summary that voids a retention certificate. The
+// certificate is marked as reverted once SUNAT accepts the summary.
+func (s *ReversalService) RevertRetention(id, reason string) (*domain.ReversalSummary, error) {
+	retention, err := s.retentionRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckRevertible(retention.Series, retention.Number, retention.Status); err != nil {
+		return nil, err
+	}
+	return s.send(retention.Issuer, retention.IssueDate, domain.ReversalLine{
+		DocumentID: retention.ID,
+		DocType:    domain.DocTypeRetention,
+		Series:     retention.Series,
+		Number:     retention.Number,
+		Reason:     reason,
+	})
+}
+
+// RevertPerception sends a reversal summary that voids a perception receipt. The receipt is
+// marked as reverted once SUNAT accepts the summary.
+func (s *ReversalService) RevertPerception(id, reason string) (*domain.ReversalSummary, error) {
+	receipt, err := s.perceptionRepo.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckRevertible(receipt.Series, receipt.Number, receipt.Status); err != nil {
+		return nil, err
+	}
+	return s.send(receipt.Issuer, receipt.IssueDate, domain.ReversalLine{
+		DocumentID: receipt.ID,
+		DocType:    domain.DocTypePerceptionReceipt,
+		Series:     receipt.Series,
+		Number:     receipt.Number,
+		Reason:     reason,
+	})
+}
+
+// send numbers, signs and sends a reversal summary with sendSummary. The summary is saved
+// before it is sent, so that its number is not reused when sending fails.
+func (s *ReversalService) send(issuer domain.Issuer, referenceDate time.Time, lines ...domain.ReversalLine) (*domain.ReversalSummary, error) {
+	ctx := context.Background()
+	now := time.Now()
+	count, err := s.reversalRepo.CountByIssueDate(ctx, issuer.RUC, now)
+	if err != nil {
+		return nil, fmt.Errorf("error al numerar el resumen de reversiones: %w", err)
+	}
+	summary := &domain.ReversalSummary{
+		ID:            fmt.Sprintf("RR-%s-%d", now.Format("20060102"), count+1),
+		Issuer:        issuer,
+		ReferenceDate: referenceDate,
+		IssueDate:     now,
+		Lines:         lines,
+		Status:        "RECIBIDO",
+	}
+	if err := summary.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.reversalRepo.Save(ctx, summary); err != nil {
+		return nil, fmt.Errorf("error al guardar el resumen de reversiones: %w", err)
+	}
+
+	ublSummary, err := ubl.BuildReversalSummary(summary)
+	if err != nil {
+		s.reject(summary, err)
+		return nil, fmt.Errorf("error al construir UBL del resumen de reversiones: %w", err)
+	}
+	unsignedXML, err := xml.MarshalIndent(ublSummary, "", "  ")
+	if err != nil {
+		s.reject(summary, err)
+		return nil, fmt.Errorf("error al generar XML del resumen de reversiones: %w", err)
+	}
+	signedXML, err := s.signer.Sign(unsignedXML)
+	if err != nil {
+		s.reject(summary, err)
+		return nil, fmt.Errorf("error al firmar XML del resumen de reversiones: %w", err)
+	}
+	summary.Status = "FIRMADO"
+
+	fileName := fmt.Sprintf("%s-%s.xml", issuer.RUC, summary.ID)
+	ticket, err := s.sunatClient.SendSummary(fileName, signedXML)
+	if err != nil {
+		s.reject(summary, err)
+		return nil, fmt.Errorf("error al enviar el resumen de reversiones a SUNAT: %w", err)
+	}
+
+	if err := s.reversalRepo.UpdateStatus(ctx, summary.ID, "ENVIADO", ticket, ""); err != nil {
+		return nil, err
+	}
+	summary.TicketID = ticket
+	summary.Status = "ENVIADO"
+	return summary, nil
+}
+
+// reject marks a saved reversal summary as rejected, with the cause, when it could not be sent.
+func (s *ReversalService) reject(summary *domain.ReversalSummary, cause error) {
+	summary.Status = "RECHAZADO"
+	if err := s.reversalRepo.UpdateStatus(context.Background(), summary.ID, summary.Status, "", cause.Error()); err != nil {
+		fmt.Printf("No se pudo actualizar el estado del resumen de reversiones %s: %v\n", summary.ID, err)
+	}
+}
+
+// GetStatus queries the ticket of a reversal summary. When SUNAT accepts it, the reverted
+// certificates are marked as REVERTIDO.
+func (s *ReversalService) GetStatus(id string) (*domain.ReversalSummary, error) {
+	ctx := context.Background()
+	summary, err := s.reversalRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status != "ENVIADO" {
+		return summary, nil
+	}
+
+	status, err := s.sunatClient.GetStatus(summary.TicketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar estado en SUNAT: %w", err)
+	}
+	switch status.StatusCode {
+	case "0": // Processed
+		summary.Status = "ACEPTADO"
+	case "98": // In progress
+		return summary, nil
+	case "99": // Processed with errors
+		summary.Status = "RECHAZADO"
+	default:
+		return nil, fmt.Errorf("código de respuesta %q desconocido para el ticket %s", status.StatusCode, summary.TicketID)
+	}
+
+	if summary.Status == "ACEPTADO" {
+		for _, line := range summary.Lines {
+			if err := s.markReverted(ctx, line); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.reversalRepo.UpdateStatus(ctx, summary.ID, summary.Status, summary.TicketID, status.StatusMessage); err != nil {
+		return nil, err
+	}
+	summary.StatusMessage = status.StatusMessage
+	return summary, nil
+}
+
+// markReverted updates the status of a certificate voided by an accepted reversal summary.
+func (s *ReversalService) markReverted(ctx context.Context, line domain.ReversalLine) error {
+	switch line.DocType {
+	case domain.DocTypeRetention:
+		retention, err := s.retentionRepo.FindByID(ctx, line.DocumentID)
+		if err != nil {
+			return err
+		}
+		return s.retentionRepo.UpdateStatus(ctx, retention.ID, domain.StatusReverted, retention.TicketID)
+	case domain.DocTypePerceptionReceipt:
+		receipt, err := s.perceptionRepo.FindByID(ctx, line.DocumentID)
+		if err != nil {
+			return err
+		}
+		return s.perceptionRepo.UpdateStatus(ctx, receipt.ID, domain.StatusReverted, receipt.TicketID, receipt.StatusMessage)
+	default:
+		return fmt.Errorf("tipo de comprobante %s no admite reversión", line.DocType)
+	}
+}
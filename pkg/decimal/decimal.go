@@ -0,0 +1,337 @@
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxScale is the maximum number of decimals a Decimal keeps: SUNAT accepts up to 10
+// decimals in unit values and quantities. Longer results are rounded half away from zero.
+const MaxScale = 10
+
+// maxExponent bounds the exponent accepted by Parse, so that input such as "1e999999999"
+// cannot build huge numbers.
+const maxExponent = MaxScale + 18
+
+// Decimal is an exact decimal number, coef x 10^-scale. Unlike float64 it represents amounts
+// such as 2.675 exactly, so rounding and XML output are predictable. The zero value is 0, and
+// operations never modify their operands.
+type Decimal struct {
+	coef  *big.Int // nil is zero
+	scale int32    // 0 to MaxScale
+}
+
+// Zero is the decimal 0.
+var Zero = Decimal{}
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// New returns value x 10^-scale, e.g. New(2675, 3) is 2.675.
+func New(value int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(value), scale)
+}
+
+// NewFromInt returns the integer as a decimal.
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// NewFromFloat returns the shortest decimal that represents the float, e.g. 0.1 is 0.1 and
+// not 0.1000000000000000055511151231257827.
+func NewFromFloat(f float64) Decimal {
+	return MustParse(strconv.FormatFloat(f, 'f', -1, 64)).trim()
+}
+
+// Parse reads a decimal such as "118", "-2.675" or "1.5e3". Decimals beyond MaxScale are
+// rounded; exponents beyond ±28 are rejected.
+func Parse(s string) (Decimal, error) {
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	exp := 0
+	if hasExponent {
+		var err error
+		if exp, err = strconv.Atoi(exponent); err != nil {
+			return Zero, fmt.Errorf("decimal %q inválido", s)
+		}
+		if exp < -maxExponent || exp > maxExponent {
+			return Zero, fmt.Errorf("el exponente del decimal %q está fuera del rango ±%d", s, maxExponent)
+		}
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	sign := ""
+	if digits != "" && (digits[0] == '-' || digits[0] == '+') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" || strings.ContainsAny(fracPart, "+-") {
+		return Zero, fmt.Errorf("decimal %q inválido", s)
+	}
+	coef, _ := new(big.Int).SetString(sign+digits, 10)
+	scale := len(fracPart) - exp
+	if scale > math.MaxInt32 {
+		return Zero, fmt.Errorf("decimal %q con demasiados decimales", s)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return newDecimal(coef, int32(scale)), nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is meant for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// newDecimal builds a decimal, rounding it to MaxScale.
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(coef, pow10(int(-scale)))}
+	}
+	d := Decimal{coef: coef, scale: scale}
+	if scale > MaxScale {
+		return d.Round(MaxScale)
+	}
+	return d
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// int returns the coefficient, never nil.
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d expressed with a scale greater or equal to its own.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.int()
+	}
+	return new(big.Int).Mul(d.int(), pow10(int(scale-d.scale)))
+}
+
+// Add returns d + d2.
+func (d Decimal) Add(d2 Decimal) Decimal {
+	scale := max(d.scale, d2.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - d2.
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	scale := max(d.scale, d2.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Mul returns d x d2, rounded to MaxScale.
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return newDecimal(new(big.Int).Mul(d.int(), d2.int()), d.scale+d2.scale)
+}
+
+// Div returns d / d2 rounded to MaxScale, without trailing zeros. It panics if d2 is zero.
+func (d Decimal) Div(d2 Decimal) Decimal {
+	if d2.IsZero() {
+		panic("decimal: división entre cero")
+	}
+	num := new(big.Int).Mul(d.int(), pow10(int(MaxScale+d2.scale-d.scale)))
+	q, r := new(big.Int).QuoRem(num, d2.int(), new(big.Int))
+	if roundsAway(r, d2.int()) {
+		if num.Sign()*d2.int().Sign() < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return Decimal{coef: q, scale: MaxScale}.trim()
+}
+
+// roundsAway reports whether the remainder r of a division by divisor is half or more.
+func roundsAway(r, divisor *big.Int) bool {
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	return twice.CmpAbs(divisor) >= 0
+}
+
+// trim removes the trailing zeros of the decimals.
+func (d Decimal) trim() Decimal {
+	coef := d.int()
+	scale := d.scale
+	r := new(big.Int)
+	for scale > 0 {
+		q, m := new(big.Int).QuoRem(coef, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		coef = q
+		scale--
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Round returns d rounded half away from zero to the given decimals, e.g. 2.675 is 2.68 with
+// two decimals. The result keeps exactly that many decimals, so 118 is 118.00.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return Decimal{coef: d.rescale(places), scale: places}
+	}
+	divisor := pow10(int(d.scale - places))
+	q, r := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+	if roundsAway(r, divisor) {
+		if d.int().Sign() < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return Decimal{coef: q, scale: places}
+}
+
+// Scale returns the number of decimals of d.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Cmp compares d and d2 and returns -1, 0 or +1.
+func (d Decimal) Cmp(d2 Decimal) int {
+	scale := max(d.scale, d2.scale)
+	return d.rescale(scale).Cmp(d2.rescale(scale))
+}
+
+// Equal reports whether d and d2 are the same number, whatever their decimals.
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+// GreaterThan reports whether d > d2.
+func (d Decimal) GreaterThan(d2 Decimal) bool {
+	return d.Cmp(d2) > 0
+}
+
+// LessThan reports whether d < d2.
+func (d Decimal) LessThan(d2 Decimal) bool {
+	return d.Cmp(d2) < 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0. It also lets the omitzero JSON option skip zero values.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// IsPositive reports whether d > 0.
+func (d Decimal) IsPositive() bool {
+	return d.Sign() > 0
+}
+
+// IsNegative reports whether d < 0.
+func (d Decimal) IsNegative() bool {
+	return d.Sign() < 0
+}
+
+// Float64 returns the nearest float64, for the places that only need an approximation.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d with all its decimals, e.g. "118.00" or "-0.5".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// StringFixed returns d rounded to the given decimals, e.g. "118.00".
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).String()
+}
+
+// MarshalText writes d with all its decimals. It is used for XML elements and attributes.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads a decimal written by MarshalText.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON writes d as a JSON number with all its decimals.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding a number, without going through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Max returns the greatest of the decimals.
+func Max(first Decimal, rest ...Decimal) Decimal {
+	for _, d := range rest {
+		if d.GreaterThan(first) {
+			first = d
+		}
+	}
+	return first
+}
+
+// Min returns the smallest of the decimals.
+func Min(first Decimal, rest ...Decimal) Decimal {
+	for _, d := range rest {
+		if d.LessThan(first) {
+			first = d
+		}
+	}
+	return first
+}
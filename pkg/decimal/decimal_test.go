@@ -0,0 +1,200 @@
+package decimal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"118", "118"},
+		{"-2.675", "-2.675"},
+		{"+0.05", "0.05"},
+		{"1.5e3", "1500"},
+		{"1.5E-3", "0.0015"},
+		{"25e-1", "2.5"},
+		{"1e28", "10000000000000000000000000000"},
+		{"1e-28", "0.0000000000"},
+		{"0.123456789012", "0.1234567890"},
+		{"0.000000000050", "0.0000000001"},
+		{".5", "0.5"},
+		{"5.", "5"},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"-",
+		"abc",
+		"1.2.3",
+		"1,5",
+		"0x10",
+		"1.-5",
+		"1e",
+		"1e1.5",
+		"1e29",
+		"1e-29",
+		"1e999999999",
+		"1e-3000000000",
+		"NaN",
+	} {
+		if d, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want error", in, d)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"2.675", 2, "2.68"},
+		{"-2.675", 2, "-2.68"},
+		{"2.665", 2, "2.67"},
+		{"2.6749", 2, "2.67"},
+		{"-2.6749", 2, "-2.67"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"0.4", 0, "0"},
+		{"0.995", 2, "1.00"},
+		{"118", 2, "118.00"},
+		{"1.5", 3, "1.500"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).Round(tt.places).String(); got != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"1", "3", "0.3333333333"},
+		{"2", "3", "0.6666666667"},
+		{"-2", "3", "-0.6666666667"},
+		{"2", "-3", "-0.6666666667"},
+		{"-2", "-3", "0.6666666667"},
+		{"10", "4", "2.5"},
+		{"118", "1.18", "100"},
+		{"0", "7", "0"},
+		{"1", "0.0000000003", "3333333333.3333333333"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.a).Div(MustParse(tt.b)).String(); got != tt.want {
+			t.Errorf("%s / %s = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDivByZeroPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Div by zero did not panic")
+		}
+	}()
+	NewFromInt(1).Div(Zero)
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("0.1"), MustParse("0.2")
+	if got := a.Add(b); !got.Equal(MustParse("0.3")) {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.1" {
+		t.Errorf("0.1 - 0.2 = %s, want -0.1", got)
+	}
+	if got := MustParse("1.18").Mul(MustParse("100.5")).String(); got != "118.590" {
+		t.Errorf("1.18 x 100.5 = %s, want 118.590", got)
+	}
+	if !MustParse("118.00").Equal(NewFromInt(118)) {
+		t.Error("118.00 != 118")
+	}
+	if got := Max(a, b, Zero); !got.Equal(b) {
+		t.Errorf("Max = %s, want 0.2", got)
+	}
+	if got := Min(a, b, Zero); !got.IsZero() {
+		t.Errorf("Min = %s, want 0", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		d    Decimal
+		want string
+	}{
+		{Zero, "0"},
+		{New(5, 2), "0.05"},
+		{New(-5, 2), "-0.05"},
+		{New(5, 4), "0.0005"},
+		{New(11800, 2), "118.00"},
+		{New(-2675, 3), "-2.675"},
+		{NewFromInt(-7), "-7"},
+		{NewFromFloat(0.1), "0.1"},
+		{Zero.Round(2), "0.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+	if got := MustParse("2.675").StringFixed(2); got != "2.68" {
+		t.Errorf("StringFixed(2) = %s, want 2.68", got)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type amounts struct {
+		Price    Decimal `json:"precio"`
+		Discount Decimal `json:"descuento,omitzero"`
+	}
+	for _, in := range []string{
+		`{"precio":118.00}`,
+		`{"precio":-0.05,"descuento":2.675}`,
+		`{"precio":0.0000000001}`,
+	} {
+		var v amounts
+		if err := json.Unmarshal([]byte(in), &v); err != nil {
+			t.Errorf("Unmarshal(%s): %v", in, err)
+			continue
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("Marshal: %v", err)
+			continue
+		}
+		if string(out) != in {
+			t.Errorf("round trip of %s = %s", in, out)
+		}
+	}
+
+	var v amounts
+	if err := json.Unmarshal([]byte(`{"precio":"2.675","descuento":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal of a string: %v", err)
+	}
+	if v.Price.String() != "2.675" || !v.Discount.IsZero() {
+		t.Errorf("Unmarshal of a string = %s, %s", v.Price, v.Discount)
+	}
+	if err := json.Unmarshal([]byte(`{"precio":1e999999999}`), &v); err == nil || !strings.Contains(err.Error(), "exponente") {
+		t.Errorf("Unmarshal of a huge exponent: %v, want exponent error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package numletras
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxAmount is the largest integer part Words can convert.
+const MaxAmount = 999_999_999_999
+
+// currencyName is the singular and plural name of a currency.
+type currencyName struct {
+	singular string
+	plural   string
+}
+
+// currencies holds the names of the supported ISO 4217 currencies.
+var currencies = map[string]currencyName{
+	"PEN": {"SOL", "SOLES"},
+	"USD": {"DÓLAR AMERICANO", "DÓLARES AMERICANOS"},
+	"EUR": {"EURO", "EUROS"},
+}
+
+var (
+	units = [...]string{
+		"", "UNO", "DOS", "TRES", "CUATRO", "CINCO", "SEIS", "SIETE", "OCHO", "NUEVE",
+		"DIEZ", "ONCE", "DOCE", "TRECE", "CATORCE", "QUINCE", "DIECISÉIS", "DIECISIETE", "DIECIOCHO", "DIECINUEVE",
+		"VEINTE", "VEINTIUNO", "VEINTIDÓS", "VEINTITRÉS", "VEINTICUATRO", "VEINTICINCO", "VEINTISÉIS", "VEINTISIETE", "VEINTIOCHO", "VEINTINUEVE",
+	}
+	tens = [...]string{
+		"", "", "", "TREINTA", "CUARENTA", "CINCUENTA", "SESENTA", "SETENTA", "OCHENTA", "NOVENTA",
+	}
+	hundreds = [...]string{
+		"", "CIENTO", "DOSCIENTOS", "TRESCIENTOS", "CUATROCIENTOS", "QUINIENTOS", "SEISCIENTOS", "SETECIENTOS", "OCHOCIENTOS", "NOVECIENTOS",
+	}
+)
+
+// Amount returns the amount in words with the cents as a fraction of 100 and the currency
+// name, e.g. 118 PEN is CIENTO DIECIOCHO CON 00/100 SOLES. The name is singular when the
+// integer part is one.
+func Amount(amount decimal.Decimal, currency string) (string, error) {
+	name, ok := currencies[currency]
+	if !ok {
+		return "", fmt.Errorf("moneda %q sin nombre en letras", currency)
+	}
+	if amount.IsNegative() {
+		return "", fmt.Errorf("el monto %s no puede ser negativo", amount)
+	}
+	intPart, cents, _ := strings.Cut(amount.StringFixed(2), ".")
+	integer, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || integer > MaxAmount {
+		return "", fmt.Errorf("el monto %s excede el máximo convertible a letras", amount)
+	}
+
+	plural := name.plural
+	if integer == 1 {
+		plural = name.singular
+	}
+	return fmt.Sprintf("%s CON %s/100 %s", Words(integer), cents, plural), nil
+}
+
+// Words returns a non-negative integer up to MaxAmount in Spanish words, in upper case, e.g.
+// 21 is VEINTIUNO and 21000 is VEINTIÚN MIL.
+func Words(n int64) string {
+	if n == 0 {
+		return "CERO"
+	}
+	var parts []string
+	if millions := n / 1_000_000; millions > 0 {
+		if millions == 1 {
+			parts = append(parts, "UN MILLÓN")
+		} else {
+			parts = append(parts, apocope(thousandsWords(millions))+" MILLONES")
+		}
+	}
+	if rest := n % 1_000_000; rest > 0 {
+		parts = append(parts, thousandsWords(rest))
+	}
+	return strings.Join(parts, " ")
+}
+
+// thousandsWords converts an integer below one million.
+func thousandsWords(n int64) string {
+	var parts []string
+	if thousands := n / 1000; thousands > 0 {
+		if thousands == 1 {
+			parts = append(parts, "MIL")
+		} else {
+			parts = append(parts, apocope(hundredsWords(thousands))+" MIL")
+		}
+	}
+	if rest := n % 1000; rest > 0 {
+		parts = append(parts, hundredsWords(rest))
+	}
+	return strings.Join(parts, " ")
+}
+
+// hundredsWords converts an integer below one thousand.
+func hundredsWords(n int64) string {
+	if n == 100 {
+		return "CIEN"
+	}
+	var parts []string
+	if h := n / 100; h > 0 {
+		parts = append(parts, hundreds[h])
+	}
+	switch rest := n % 100; {
+	case rest == 0:
+	case rest < 30:
+		parts = append(parts, units[rest])
+	case rest%10 == 0:
+		parts = append(parts, tens[rest/10])
+	default:
+		parts = append(parts, tens[rest/10]+" Y "+units[rest%10])
+	}
+	return strings.Join(parts, " ")
+}
+
+// apocope shortens a trailing UNO before a noun or MIL: VEINTIUNO becomes VEINTIÚN and
+// TREINTA Y UNO becomes TREINTA Y UN.
+func apocope(words string) string {
+	if strings.HasSuffix(words, "VEINTIUNO") {
+		return strings.TrimSuffix(words, "VEINTIUNO") + "VEINTIÚN"
+	}
+	if strings.HasSuffix(words, "UNO") {
+		return strings.TrimSuffix(words, "O")
+	}
+	return words
+}
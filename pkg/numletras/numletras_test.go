@@ -0,0 +1,92 @@
+package numletras
+
+import (
+	"FacturacionSunat/pkg/decimal"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "CERO"},
+		{1, "UNO"},
+		{15, "QUINCE"},
+		{16, "DIECISÉIS"},
+		{21, "VEINTIUNO"},
+		{22, "VEINTIDÓS"},
+		{30, "TREINTA"},
+		{31, "TREINTA Y UNO"},
+		{100, "CIEN"},
+		{101, "CIENTO UNO"},
+		{118, "CIENTO DIECIOCHO"},
+		{500, "QUINIENTOS"},
+		{1000, "MIL"},
+		{1001, "MIL UNO"},
+		{21000, "VEINTIÚN MIL"},
+		{31000, "TREINTA Y UN MIL"},
+		{100000, "CIEN MIL"},
+		{101000, "CIENTO UN MIL"},
+		{1_000_000, "UN MILLÓN"},
+		{1_001_000, "UN MILLÓN MIL"},
+		{2_000_000, "DOS MILLONES"},
+		{21_000_000, "VEINTIÚN MILLONES"},
+		{100_000_000, "CIEN MILLONES"},
+		{1_000_000_000, "MIL MILLONES"},
+		{21_000_000_000, "VEINTIÚN MIL MILLONES"},
+		{MaxAmount, "NOVECIENTOS NOVENTA Y NUEVE MIL NOVECIENTOS NOVENTA Y NUEVE MILLONES NOVECIENTOS NOVENTA Y NUEVE MIL NOVECIENTOS NOVENTA Y NUEVE"},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.n); got != tt.want {
+			t.Errorf("Words(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"118", "PEN", "CIENTO DIECIOCHO CON 00/100 SOLES"},
+		{"1", "PEN", "UNO CON 00/100 SOL"},
+		{"1.50", "USD", "UNO CON 50/100 DÓLAR AMERICANO"},
+		{"2.05", "USD", "DOS CON 05/100 DÓLARES AMERICANOS"},
+		{"0.50", "EUR", "CERO CON 50/100 EUROS"},
+		{"21", "EUR", "VEINTIUNO CON 00/100 EUROS"},
+		{"0.995", "PEN", "UNO CON 00/100 SOL"},
+		{"1.995", "PEN", "DOS CON 00/100 SOLES"},
+		{"999999999999.99", "PEN", "NOVECIENTOS NOVENTA Y NUEVE MIL NOVECIENTOS NOVENTA Y NUEVE MILLONES NOVECIENTOS NOVENTA Y NUEVE MIL NOVECIENTOS NOVENTA Y NUEVE CON 99/100 SOLES"},
+	}
+	for _, tt := range tests {
+		got, err := Amount(decimal.MustParse(tt.amount), tt.currency)
+		if err != nil {
+			t.Errorf("Amount(%s, %s): %v", tt.amount, tt.currency, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Amount(%s, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestAmountErrors(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+	}{
+		{"-1", "PEN"},
+		{"-0.01", "USD"},
+		{"1", "GBP"},
+		{"1", ""},
+		{"1000000000000", "PEN"},
+		{"999999999999.995", "PEN"},
+	}
+	for _, tt := range tests {
+		if got, err := Amount(decimal.MustParse(tt.amount), tt.currency); err == nil {
+			t.Errorf("Amount(%s, %q) = %q, want error", tt.amount, tt.currency, got)
+		}
+	}
+}
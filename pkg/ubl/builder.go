@@ -2,13 +2,21 @@ package ubl
 
 import (
 	"FacturacionSunat/internal/domain"
+	"FacturacionSunat/pkg/decimal"
 	"encoding/xml"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // BuildInvoice transforms a domain.Invoice into a UBL Invoice structure ready for XML marshalling.
 func BuildInvoice(inv *domain.Invoice) (*Invoice, error) {
+	percents, err := taxPercents(inv.Issuer.Regime, inv.IssueDate)
+	if err != nil {
+		return nil, err
+	}
+
 	// Default namespaces and attributes
 	ublInvoice := &Invoice{
 		Xmlns:           "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2",
@@ -26,7 +34,7 @@ func BuildInvoice(inv *domain.Invoice) (*Invoice, error) {
 			SchemeName:       "SUNAT:Identificador de Tipo de Operación",
 			SchemeAgencyName: "PE:SUNAT",
 			SchemeURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo17",
-			Value:            "0101", // For Boleta, Venta Interna
+			Value:            operationType(inv.OperationType),
 		},
 		ID:        fmt.Sprintf("%s-%d", inv.Series, inv.Number),
 		IssueDate: inv.IssueDate.Format("2006-01-02"),
@@ -94,39 +102,97 @@ func BuildInvoice(inv *domain.Invoice) (*Invoice, error) {
 		LegalMonetaryTotal: &MonetaryTotal{
 			PayableAmount:       &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Total},
 			LineExtensionAmount: &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Gross},
-			TaxInclusiveAmount:  &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Total + inv.Totals.IGV}, // Assuming Total is Net + IGV
-		},
-	}
-
-	// Tax Totals
-	igvTaxTotal := &TaxTotal{
-		TaxAmount: &Amount{CurrencyID: inv.Currency, Value: inv.Totals.IGV},
-		TaxSubtotal: []*TaxSubtotal{{
-			TaxableAmount: &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Gross},
-			TaxAmount:     &Amount{CurrencyID: inv.Currency, Value: inv.Totals.IGV},
-			TaxCategory: &TaxCategory{
-				ID:               "S",
-				SchemeID:         "UN/ECE 5305",
-				SchemeName:       "Tax Category Identifier",
-				SchemeAgencyName: "United Nations Economic Commission for Europe",
-				Percent:          18.00, // Assuming 18% IGV
-				TaxExemptionReasonCode: &TaxExemptionReasonCode{
-					ListAgencyName: "PE:SUNAT",
-					ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-					ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-					Value:          "10", // Gravado - Operación Onerosa
-				},
-				TaxScheme: &TaxScheme{
-					ID:             "1000",
-					SchemeID:       "UN/ECE 5153",
-					SchemeAgencyID: "6",
-					Name:           "IGV",
-					TaxTypeCode:    "VAT",
-				},
+			TaxInclusiveAmount:  &Amount{CurrencyID: inv.Currency, Value: inv.Totals.TaxInclusive},
+		},
+		AllowanceCharges: buildAllowanceCharges(inv.AllowanceCharges, inv.Currency),
+	}
+	if inv.Totals.Allowances.IsPositive() {
+		ublInvoice.LegalMonetaryTotal.AllowanceTotalAmount = &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Allowances}
+	}
+	if inv.Totals.Charges.IsPositive() {
+		ublInvoice.LegalMonetaryTotal.ChargeTotalAmount = &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Charges}
+	}
+
+	// Destination and Incoterm of exports
+	ublInvoice.Delivery, ublInvoice.DeliveryTerms = buildDelivery(inv.Export)
+
+	// Transport data of a factura guía
+	if s := inv.Shipment; s != nil {
+		if ublInvoice.Delivery == nil {
+			ublInvoice.Delivery = &Delivery{}
+		}
+		ublInvoice.Delivery.Shipment = buildShipment(s)
+	}
+
+	// Trip of a freight transport service subject to detraction
+	if d := inv.Detraction; d != nil && d.Code == domain.DetractionFreightTransport && inv.Freight != nil {
+		if ublInvoice.Delivery == nil {
+			ublInvoice.Delivery = &Delivery{}
+		}
+		addFreightDelivery(ublInvoice.Delivery, inv.Freight)
+	}
+
+	// Payment terms: cash or credit with installments
+	ublInvoice.PaymentTerms = append(ublInvoice.PaymentTerms, buildPaymentTerms(inv.PaymentTerms, inv.Currency)...)
+
+	// Perception charged by the issuer as perception agent, always in PEN
+	if p := inv.Perception; p != nil {
+		ublInvoice.PaymentTerms = append(ublInvoice.PaymentTerms, &PaymentTerms{
+			ID:     "Percepcion",
+			Amount: &Amount{CurrencyID: "PEN", Value: p.TotalWithPerception},
+		})
+		ublInvoice.AllowanceCharges = append(ublInvoice.AllowanceCharges, &AllowanceCharge{
+			ChargeIndicator: true,
+			AllowanceChargeReasonCode: &AllowanceChargeReasonCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Cargo/descuento",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo53",
+				Value:          p.Code,
 			},
-		}},
+			MultiplierFactorNumeric: optionalDecimal(p.Percent.Div(decimal.NewFromInt(100))),
+			Amount:                  &Amount{CurrencyID: "PEN", Value: p.Amount},
+			BaseAmount:              &Amount{CurrencyID: "PEN", Value: p.BaseAmount},
+		})
+	}
+
+	// Legends
+	ublInvoice.Notes = buildNotes(inv.Legends)
+
+	// Detraction (SPOT)
+	if d := inv.Detraction; d != nil {
+		ublInvoice.PaymentMeans = append(ublInvoice.PaymentMeans, &PaymentMeans{
+			ID: "Detraccion",
+			PaymentMeansCode: &PaymentMeansCode{
+				ListName:       "Medio de pago",
+				ListAgencyName: "PE:SUNAT",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo59",
+				Value:          d.PaymentMeansCode,
+			},
+			PayeeFinancialAccount: &PayeeFinancialAccount{ID: d.Account},
+		})
+		ublInvoice.PaymentTerms = append(ublInvoice.PaymentTerms, &PaymentTerms{
+			ID: "Detraccion",
+			PaymentMeansID: &PaymentMeansID{
+				SchemeName:       "SUNAT:Codigo de detraccion",
+				SchemeAgencyName: "PE:SUNAT",
+				SchemeURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo54",
+				Value:            d.Code,
+			},
+			PaymentPercent: optionalDecimal(d.Percent),
+			Amount:         &Amount{CurrencyID: "PEN", Value: d.Amount},
+		})
 	}
-	ublInvoice.TaxTotals = append(ublInvoice.TaxTotals, igvTaxTotal)
+
+	// Prepayments deducted from this invoice
+	refs, payments := buildPrepayments(inv.Prepayments, inv.Issuer.RUC, inv.Currency)
+	ublInvoice.AdditionalDocumentReference = append(ublInvoice.AdditionalDocumentReference, refs...)
+	ublInvoice.PrepaidPayments = payments
+	if inv.Totals.Prepaid.IsPositive() {
+		ublInvoice.LegalMonetaryTotal.PrepaidAmount = &Amount{CurrencyID: inv.Currency, Value: inv.Totals.Prepaid}
+	}
+
+	// Tax Totals, one subtotal per tax scheme
+	ublInvoice.TaxTotals = append(ublInvoice.TaxTotals, buildTaxTotal(inv.Totals, percents, inv.Currency))
 
 	// Invoice Lines
 	for i, line := range inv.Lines {
@@ -134,54 +200,19 @@ func BuildInvoice(inv *domain.Invoice) (*Invoice, error) {
 			ID:                  strconv.Itoa(i + 1),
 			InvoicedQuantity:    &Quantity{UnitCode: "NIU", Value: line.Quantity}, // Assuming NIU, should be configurable
 			LineExtensionAmount: &Amount{CurrencyID: inv.Currency, Value: line.TotalValue},
-			PricingReference: &PricingReference{
-				AlternativeConditionPrice: []*Price{{
-					PriceAmount: &Amount{CurrencyID: inv.Currency, Value: line.UnitPrice},
-					PriceTypeCode: &PriceTypeCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Indicador de Tipo de Precio",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo16",
-						Value:          "01", // Precio unitario (incluye IGV)
-					},
-				}},
-			},
+			PricingReference:    buildPricingReference(line, inv.Currency),
+			AllowanceCharges:    buildAllowanceCharges(line.AllowanceCharges, inv.Currency),
 			Item: &Item{
 				Description: line.Description,
 				// SellersItemIdentification: &SellersItemIdentification{ID: ""},
 				// CommodityClassification: &CommodityClassification{ItemClassificationCode: &ItemClassificationCode{Value: ""}},
+				AdditionalItemProperties: buildAdditionalItemProperties(line.Properties),
 			},
-			Price: &Price{PriceAmount: &Amount{CurrencyID: inv.Currency, Value: line.UnitPrice}},
+			Price: buildPrice(line, inv.Currency),
 		}
 
-		// Line Tax Totals (simplified for now, assuming only IGV)
-		lineTaxTotal := &TaxTotal{
-			TaxAmount: &Amount{CurrencyID: inv.Currency, Value: line.IGV},
-			TaxSubtotal: []*TaxSubtotal{{
-				TaxableAmount: &Amount{CurrencyID: inv.Currency, Value: line.TotalValue},
-				TaxAmount:     &Amount{CurrencyID: inv.Currency, Value: line.IGV},
-				TaxCategory: &TaxCategory{
-					ID:               "S",
-					SchemeID:         "UN/ECE 5305",
-					SchemeName:       "Tax Category Identifier",
-					SchemeAgencyName: "United Nations Economic Commission for Europe",
-					Percent:          18.00,
-					TaxExemptionReasonCode: &TaxExemptionReasonCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-						Value:          "10", // Gravado - Operación Onerosa
-					},
-					TaxScheme: &TaxScheme{
-						ID:             "1000",
-						SchemeID:       "UN/ECE 5153",
-						SchemeAgencyID: "6",
-						Name:           "IGV",
-						TaxTypeCode:    "VAT",
-					},
-				},
-			}},
-		}
-		ublLine.TaxTotals = append(ublLine.TaxTotals, lineTaxTotal)
+		// Line Tax Totals with the catalog 07 affectation of the line
+		ublLine.TaxTotals = append(ublLine.TaxTotals, buildLineTaxTotal(line, percents, inv.Currency))
 
 		ublInvoice.InvoiceLines = append(ublInvoice.InvoiceLines, ublLine)
 	}
@@ -189,15 +220,105 @@ func BuildInvoice(inv *domain.Invoice) (*Invoice, error) {
 	// Set UBLExtensions for signature
 	ublInvoice.UBLExtensions = &UBLExtensions{
 		UBLExtension: &UBLExtension{
-			ExtensionContent: &ExtensionContent{XMLName: xml.Name{Local: "ds:Signature"}}, // Placeholder for goxades to fill
+			ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}}, // Placeholder for goxades to fill
+		},
+	}
+
+	return ublInvoice, nil
+}
+
+// BuildPurchaseSettlement transforms a domain.PurchaseSettlement into a UBL Invoice with type
+// 04. The parties are inverted: the seller is the supplier and the issuer the customer.
+func BuildPurchaseSettlement(ps *domain.PurchaseSettlement) (*Invoice, error) {
+	percents, err := taxPercents(ps.Issuer.Regime, ps.IssueDate)
+	if err != nil {
+		return nil, err
+	}
+	ublInvoice, err := BuildInvoice(&domain.Invoice{
+		Type:          domain.DocTypePurchaseSettlement,
+		OperationType: domain.OperationPurchase,
+		Series:        ps.Series,
+		Number:        ps.Number,
+		IssueDate:     ps.IssueDate,
+		Currency:      ps.Currency,
+		Issuer:        ps.Issuer,
+		Lines:         ps.Lines,
+		Legends:       ps.Legends,
+		Totals:        ps.Totals,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Seller without RUC
+	sellerDocType := getDocType(ps.Seller.DocType)
+	ublInvoice.AccountingSupplierParty = &Supplier{
+		CustomerAssignedAccountID: ps.Seller.DocNum,
+		AdditionalAccountID:       sellerDocType,
+		Party: &Party{
+			PartyLegalEntity: &PartyLegalEntity{RegistrationName: ps.Seller.Name},
+			PartyTaxScheme: &PartyTaxScheme{
+				RegistrationName: ps.Seller.Name,
+				CompanyID: &CompanyID{
+					SchemeID:         sellerDocType,
+					SchemeName:       "SUNAT:Identificador de Documento de Identidad",
+					SchemeAgencyName: "PE:SUNAT",
+					SchemeURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo06",
+					Value:            ps.Seller.DocNum,
+				},
+				TaxScheme: &TaxScheme{ID: "-"},
+			},
+		},
+	}
+
+	// Issuer as buyer
+	ublInvoice.AccountingCustomerParty = &Customer{
+		CustomerAssignedAccountID: ps.Issuer.RUC,
+		AdditionalAccountID:       "6", // RUC
+		Party: &Party{
+			PartyName:        &PartyName{Name: ps.Issuer.Name},
+			PartyLegalEntity: &PartyLegalEntity{RegistrationName: ps.Issuer.Name},
+			PartyTaxScheme: &PartyTaxScheme{
+				RegistrationName: ps.Issuer.Name,
+				CompanyID: &CompanyID{
+					SchemeID:         "6",
+					SchemeName:       "SUNAT:Identificador de Documento de Identidad",
+					SchemeAgencyName: "PE:SUNAT",
+					SchemeURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo06",
+					Value:            ps.Issuer.RUC,
+				},
+				RegistrationAddress: &RegistrationAddress{AddressTypeCode: "0000"}, // Default for fiscal address
+				TaxScheme:           &TaxScheme{ID: "-"},
+			},
 		},
 	}
 
+	// Place where the goods were bought
+	ublInvoice.Delivery = &Delivery{
+		DeliveryLocation: &DeliveryLocation{Address: buildUbigeoAddress(ps.OperationPlace)},
+	}
+
+	// IGV and income tax retained by the buyer
+	withholding := &TaxTotal{TaxAmount: &Amount{CurrencyID: ps.Currency, Value: domain.Round2(ps.IGVRetained.Add(ps.IncomeTaxRetained))}}
+	if ps.IGVRetained.IsPositive() {
+		withholding.TaxSubtotal = append(withholding.TaxSubtotal, buildTaxSubtotal(domain.TaxSchemeIGV, "", ps.Totals.Taxable, ps.IGVRetained, percents[domain.TaxSchemeIGV], ps.Currency))
+	}
+	withholding.TaxSubtotal = append(withholding.TaxSubtotal, buildTaxSubtotal(domain.TaxSchemeIncomeTax, "", ps.Totals.Gross, ps.IncomeTaxRetained, domain.IncomeTaxRetentionPercent, ps.Currency))
+	ublInvoice.WithholdingTaxTotals = append(ublInvoice.WithholdingTaxTotals, withholding)
+
 	return ublInvoice, nil
 }
 
 // BuildCreditNote transforms a domain.CreditNote into a UBL CreditNote structure.
 func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
+	if !domain.IsCreditNoteType(cn.DiscrepancyResponse.TypeCode) {
+		return nil, fmt.Errorf("tipo de nota de crédito %q no soportado", cn.DiscrepancyResponse.TypeCode)
+	}
+	percents, err := taxPercents(cn.Issuer.Regime, cn.DiscrepancyResponse.TaxDate(cn.IssueDate))
+	if err != nil {
+		return nil, err
+	}
+
 	ublCreditNote := &CreditNote{
 		Xmlns:     "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2",
 		XmlnsCAC:  CAC,
@@ -210,7 +331,7 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 		XmlnsXSI:  XSI,
 		UBLExtensions: &UBLExtensions{
 			UBLExtension: &UBLExtension{
-				ExtensionContent: &ExtensionContent{XMLName: xml.Name{Local: "ds:Signature"}},
+				ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
 			},
 		},
 		UBLVersionID:    "2.1",
@@ -218,6 +339,7 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 		ID:              fmt.Sprintf("%s-%d", cn.Series, cn.Number),
 		IssueDate:       cn.IssueDate.Format("2006-01-02"),
 		IssueTime:       cn.IssueDate.Format("15:04:05"),
+		Notes:           buildNotes(cn.Legends),
 		DocumentCurrencyCode: &DocumentCurrencyCode{
 			ListID:         "ISO 4217 Alpha",
 			ListName:       "Currency",
@@ -225,12 +347,17 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 			Value:          cn.Currency,
 		},
 		DiscrepancyResponse: &DiscrepancyResponse{
-			ReferenceID:  cn.DiscrepancyResponse.ReferenceID,
-			ResponseCode: cn.DiscrepancyResponse.TypeCode,
-			Description:  cn.DiscrepancyResponse.Description,
+			ReferenceID: cn.DiscrepancyResponse.ReferenceID,
+			ResponseCode: &ResponseCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Tipo de nota de credito",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo09",
+				Value:          cn.DiscrepancyResponse.TypeCode,
+			},
+			Description: cn.DiscrepancyResponse.Description,
 		},
 		BillingReference: &BillingReference{
-			InvoiceDocumentReference: &InvoiceDocumentReference{ID: cn.DiscrepancyResponse.ReferenceID, DocumentTypeCode: &InvoiceTypeCode{Value: "01"}}, // Assuming 01 for Invoice
+			InvoiceDocumentReference: &InvoiceDocumentReference{ID: cn.DiscrepancyResponse.ReferenceID, DocumentTypeCode: documentTypeCode(affectedDocType(cn.DiscrepancyResponse))},
 		},
 		Signature: &Signature{
 			ID: "IDSignSP",
@@ -280,42 +407,29 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 				},
 			},
 		},
+		PaymentTerms: buildPaymentTerms(cn.PaymentTerms, cn.Currency), // Corrected installments of type 13 notes
 		LegalMonetaryTotal: &MonetaryTotal{
 			PayableAmount:       &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Total},
 			LineExtensionAmount: &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Gross},
-			TaxInclusiveAmount:  &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Total + cn.Totals.IGV},
-		},
-	}
-
-	// Tax Totals (simplified for now, assuming only IGV)
-	igvTaxTotal := &TaxTotal{
-		TaxAmount: &Amount{CurrencyID: cn.Currency, Value: cn.Totals.IGV},
-		TaxSubtotal: []*TaxSubtotal{{
-			TaxableAmount: &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Gross},
-			TaxAmount:     &Amount{CurrencyID: cn.Currency, Value: cn.Totals.IGV},
-			TaxCategory: &TaxCategory{
-				ID:               "S",
-				SchemeID:         "UN/ECE 5305",
-				SchemeName:       "Tax Category Identifier",
-				SchemeAgencyName: "United Nations Economic Commission for Europe",
-				Percent:          18.00, // Assuming 18% IGV
-				TaxExemptionReasonCode: &TaxExemptionReasonCode{
-					ListAgencyName: "PE:SUNAT",
-					ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-					ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-					Value:          "10", // Gravado - Operación Onerosa
-				},
-				TaxScheme: &TaxScheme{
-					ID:             "1000",
-					SchemeID:       "UN/ECE 5153",
-					SchemeAgencyID: "6",
-					Name:           "IGV",
-					TaxTypeCode:    "VAT",
-				},
-			},
-		}},
+			TaxInclusiveAmount:  &Amount{CurrencyID: cn.Currency, Value: cn.Totals.TaxInclusive},
+		},
+		AllowanceCharges: buildAllowanceCharges(cn.AllowanceCharges, cn.Currency),
+	}
+	if cn.Totals.Allowances.IsPositive() {
+		ublCreditNote.LegalMonetaryTotal.AllowanceTotalAmount = &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Allowances}
+	}
+	if cn.Totals.Charges.IsPositive() {
+		ublCreditNote.LegalMonetaryTotal.ChargeTotalAmount = &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Charges}
+	}
+
+	// Prepayments of an annulled invoice
+	ublCreditNote.AdditionalDocumentReference, ublCreditNote.PrepaidPayments = buildPrepayments(cn.Prepayments, cn.Issuer.RUC, cn.Currency)
+	if cn.Totals.Prepaid.IsPositive() {
+		ublCreditNote.LegalMonetaryTotal.PrepaidAmount = &Amount{CurrencyID: cn.Currency, Value: cn.Totals.Prepaid}
 	}
-	ublCreditNote.TaxTotals = append(ublCreditNote.TaxTotals, igvTaxTotal)
+
+	// Tax Totals, one subtotal per tax scheme
+	ublCreditNote.TaxTotals = append(ublCreditNote.TaxTotals, buildTaxTotal(cn.Totals, percents, cn.Currency))
 
 	// Credit Note Lines
 	for i, line := range cn.Lines {
@@ -323,52 +437,17 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 			ID:                  strconv.Itoa(i + 1),
 			InvoicedQuantity:    &Quantity{UnitCode: "NIU", Value: line.Quantity}, // Assuming NIU, should be configurable
 			LineExtensionAmount: &Amount{CurrencyID: cn.Currency, Value: line.TotalValue},
-			PricingReference: &PricingReference{
-				AlternativeConditionPrice: []*Price{{
-					PriceAmount: &Amount{CurrencyID: cn.Currency, Value: line.UnitPrice},
-					PriceTypeCode: &PriceTypeCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Indicador de Tipo de Precio",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo16",
-						Value:          "01", // Precio unitario (incluye IGV)
-					},
-				}},
-			},
+			PricingReference:    buildPricingReference(line, cn.Currency),
+			AllowanceCharges:    buildAllowanceCharges(line.AllowanceCharges, cn.Currency),
 			Item: &Item{
-				Description: line.Description,
+				Description:              line.Description,
+				AdditionalItemProperties: buildAdditionalItemProperties(line.Properties),
 			},
-			Price: &Price{PriceAmount: &Amount{CurrencyID: cn.Currency, Value: line.UnitPrice}},
+			Price: buildPrice(line, cn.Currency),
 		}
 
-		// Line Tax Totals (simplified for now, assuming only IGV)
-		lineTaxTotal := &TaxTotal{
-			TaxAmount: &Amount{CurrencyID: cn.Currency, Value: line.IGV},
-			TaxSubtotal: []*TaxSubtotal{{
-				TaxableAmount: &Amount{CurrencyID: cn.Currency, Value: line.TotalValue},
-				TaxAmount:     &Amount{CurrencyID: cn.Currency, Value: line.IGV},
-				TaxCategory: &TaxCategory{
-					ID:               "S",
-					SchemeID:         "UN/ECE 5305",
-					SchemeName:       "Tax Category Identifier",
-					SchemeAgencyName: "United Nations Economic Commission for Europe",
-					Percent:          18.00,
-					TaxExemptionReasonCode: &TaxExemptionReasonCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-						Value:          "10", // Gravado - Operación Onerosa
-					},
-					TaxScheme: &TaxScheme{
-						ID:             "1000",
-						SchemeID:       "UN/ECE 5153",
-						SchemeAgencyID: "6",
-						Name:           "IGV",
-						TaxTypeCode:    "VAT",
-					},
-				},
-			}},
-		}
-		ublLine.TaxTotals = append(ublLine.TaxTotals, lineTaxTotal)
+		// Line Tax Totals with the catalog 07 affectation of the line
+		ublLine.TaxTotals = append(ublLine.TaxTotals, buildLineTaxTotal(line, percents, cn.Currency))
 
 		ublCreditNote.CreditNoteLines = append(ublCreditNote.CreditNoteLines, ublLine)
 	}
@@ -378,6 +457,11 @@ func BuildCreditNote(cn *domain.CreditNote) (*CreditNote, error) {
 
 // BuildDebitNote transforms a domain.DebitNote into a UBL DebitNote structure.
 func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
+	percents, err := taxPercents(dn.Issuer.Regime, dn.DiscrepancyResponse.TaxDate(dn.IssueDate))
+	if err != nil {
+		return nil, err
+	}
+
 	ublDebitNote := &DebitNote{
 		Xmlns:     "urn:oasis:names:specification:ubl:schema:xsd:DebitNote-2",
 		XmlnsCAC:  CAC,
@@ -390,7 +474,7 @@ func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
 		XmlnsXSI:  XSI,
 		UBLExtensions: &UBLExtensions{
 			UBLExtension: &UBLExtension{
-				ExtensionContent: &ExtensionContent{XMLName: xml.Name{Local: "ds:Signature"}},
+				ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
 			},
 		},
 		UBLVersionID:    "2.1",
@@ -398,6 +482,7 @@ func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
 		ID:              fmt.Sprintf("%s-%d", dn.Series, dn.Number),
 		IssueDate:       dn.IssueDate.Format("2006-01-02"),
 		IssueTime:       dn.IssueDate.Format("15:04:05"),
+		Notes:           buildNotes(dn.Legends),
 		DocumentCurrencyCode: &DocumentCurrencyCode{
 			ListID:         "ISO 4217 Alpha",
 			ListName:       "Currency",
@@ -405,12 +490,17 @@ func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
 			Value:          dn.Currency,
 		},
 		DiscrepancyResponse: &DiscrepancyResponse{
-			ReferenceID:  dn.DiscrepancyResponse.ReferenceID,
-			ResponseCode: dn.DiscrepancyResponse.TypeCode,
-			Description:  dn.DiscrepancyResponse.Description,
+			ReferenceID: dn.DiscrepancyResponse.ReferenceID,
+			ResponseCode: &ResponseCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Tipo de nota de debito",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo10",
+				Value:          dn.DiscrepancyResponse.TypeCode,
+			},
+			Description: dn.DiscrepancyResponse.Description,
 		},
 		BillingReference: &BillingReference{
-			InvoiceDocumentReference: &InvoiceDocumentReference{ID: dn.DiscrepancyResponse.ReferenceID, DocumentTypeCode: &InvoiceTypeCode{Value: "01"}}, // Assuming 01 for Invoice
+			InvoiceDocumentReference: &InvoiceDocumentReference{ID: dn.DiscrepancyResponse.ReferenceID, DocumentTypeCode: documentTypeCode(affectedDocType(dn.DiscrepancyResponse))},
 		},
 		Signature: &Signature{
 			ID: "IDSignSP",
@@ -463,39 +553,12 @@ func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
 		LegalMonetaryTotal: &MonetaryTotal{
 			PayableAmount:       &Amount{CurrencyID: dn.Currency, Value: dn.Totals.Total},
 			LineExtensionAmount: &Amount{CurrencyID: dn.Currency, Value: dn.Totals.Gross},
-			TaxInclusiveAmount:  &Amount{CurrencyID: dn.Currency, Value: dn.Totals.Total + dn.Totals.IGV},
-		},
-	}
-
-	// Tax Totals (simplified for now, assuming only IGV)
-	igvTaxTotal := &TaxTotal{
-		TaxAmount: &Amount{CurrencyID: dn.Currency, Value: dn.Totals.IGV},
-		TaxSubtotal: []*TaxSubtotal{{
-			TaxableAmount: &Amount{CurrencyID: dn.Currency, Value: dn.Totals.Gross},
-			TaxAmount:     &Amount{CurrencyID: dn.Currency, Value: dn.Totals.IGV},
-			TaxCategory: &TaxCategory{
-				ID:               "S",
-				SchemeID:         "UN/ECE 5305",
-				SchemeName:       "Tax Category Identifier",
-				SchemeAgencyName: "United Nations Economic Commission for Europe",
-				Percent:          18.00, // Assuming 18% IGV
-				TaxExemptionReasonCode: &TaxExemptionReasonCode{
-					ListAgencyName: "PE:SUNAT",
-					ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-					ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-					Value:          "10", // Gravado - Operación Onerosa
-				},
-				TaxScheme: &TaxScheme{
-					ID:             "1000",
-					SchemeID:       "UN/ECE 5153",
-					SchemeAgencyID: "6",
-					Name:           "IGV",
-					TaxTypeCode:    "VAT",
-				},
-			},
-		}},
+			TaxInclusiveAmount:  &Amount{CurrencyID: dn.Currency, Value: dn.Totals.TaxInclusive},
+		},
 	}
-	ublDebitNote.TaxTotals = append(ublDebitNote.TaxTotals, igvTaxTotal)
+
+	// Tax Totals, one subtotal per tax scheme
+	ublDebitNote.TaxTotals = append(ublDebitNote.TaxTotals, buildTaxTotal(dn.Totals, percents, dn.Currency))
 
 	// Debit Note Lines
 	for i, line := range dn.Lines {
@@ -503,68 +566,838 @@ func BuildDebitNote(dn *domain.DebitNote) (*DebitNote, error) {
 			ID:                  strconv.Itoa(i + 1),
 			InvoicedQuantity:    &Quantity{UnitCode: "NIU", Value: line.Quantity}, // Assuming NIU, should be configurable
 			LineExtensionAmount: &Amount{CurrencyID: dn.Currency, Value: line.TotalValue},
-			PricingReference: &PricingReference{
-				AlternativeConditionPrice: []*Price{{
-					PriceAmount: &Amount{CurrencyID: dn.Currency, Value: line.UnitPrice},
-					PriceTypeCode: &PriceTypeCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Indicador de Tipo de Precio",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo16",
-						Value:          "01", // Precio unitario (incluye IGV)
+			PricingReference:    buildPricingReference(line, dn.Currency),
+			Item: &Item{
+				Description:              line.Description,
+				AdditionalItemProperties: buildAdditionalItemProperties(line.Properties),
+			},
+			Price: buildPrice(line, dn.Currency),
+		}
+
+		// Line Tax Totals with the catalog 07 affectation of the line
+		ublLine.TaxTotals = append(ublLine.TaxTotals, buildLineTaxTotal(line, percents, dn.Currency))
+
+		ublDebitNote.DebitNoteLines = append(ublDebitNote.DebitNoteLines, ublLine)
+	}
+
+	return ublDebitNote, nil
+}
+
+// BuildDailySummary maps a domain.DailySummary to a UBL SummaryDocuments structure.
+func BuildDailySummary(summary *domain.DailySummary) (*SummaryDocuments, error) {
+	if len(summary.Lines) == 0 {
+		return nil, fmt.Errorf("el resumen %s no tiene comprobantes", summary.ID)
+	}
+
+	ublSummary := &SummaryDocuments{
+		Xmlns:    "urn:sunat:names:specification:ubl:peru:schema:xsd:SummaryDocuments-1",
+		XmlnsCAC: CAC,
+		XmlnsCBC: CBC,
+		XmlnsDS:  DS,
+		XmlnsEXT: EXT,
+		XmlnsSAC: SAC,
+		UBLExtensions: &UBLExtensions{
+			UBLExtension: &UBLExtension{
+				ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
+			},
+		},
+		UBLVersionID:    "2.0",
+		CustomizationID: "1.1",
+		ID:              summary.ID,
+		ReferenceDate:   summary.ReferenceDate.Format("2006-01-02"),
+		IssueDate:       summary.IssueDate.Format("2006-01-02"),
+		Signature: &Signature{
+			ID: "IDSignSP",
+			SignatoryParty: &SignatoryParty{
+				PartyIdentification: &PartyIdentification{ID: summary.Issuer.RUC},
+				PartyName:           &PartyName{Name: summary.Issuer.Name},
+			},
+			DigitalSignatureAttachment: &DigitalSignatureAttachment{
+				ExternalReference: &ExternalReference{URI: "#IDSignSP"},
+			},
+		},
+		AccountingSupplierParty: &Supplier{
+			CustomerAssignedAccountID: summary.Issuer.RUC,
+			AdditionalAccountID:       "6", // RUC
+			Party: &Party{
+				PartyLegalEntity: &PartyLegalEntity{RegistrationName: summary.Issuer.Name},
+			},
+		},
+	}
+
+	for i, line := range summary.Lines {
+		percents, err := taxPercents(summary.Issuer.Regime, line.TaxDate())
+		if err != nil {
+			return nil, err
+		}
+		ublLine := &SummaryDocumentsLine{
+			LineID:           strconv.Itoa(i + 1),
+			DocumentTypeCode: &DocumentTypeCode{Value: line.DocType},
+			ID:               fmt.Sprintf("%s-%d", line.Series, line.Number),
+			AccountingCustomerParty: &SummaryCustomer{
+				CustomerAssignedAccountID: line.Recipient.DocNum,
+				AdditionalAccountID:       getDocType(line.Recipient.DocType),
+			},
+			Status:          &SummaryStatus{ConditionCode: line.Condition},
+			TotalAmount:     &Amount{CurrencyID: line.Currency, Value: line.Totals.Total},
+			BillingPayments: buildBillingPayments(line.Totals, line.Currency),
+			TaxTotals: []*TaxTotal{{
+				TaxAmount: &Amount{CurrencyID: line.Currency, Value: line.Totals.IGV},
+				TaxSubtotal: []*TaxSubtotal{{
+					TaxAmount: &Amount{CurrencyID: line.Currency, Value: line.Totals.IGV},
+					TaxCategory: &TaxCategory{
+						ID:               "S",
+						SchemeID:         "UN/ECE 5305",
+						SchemeName:       "Tax Category Identifier",
+						SchemeAgencyName: "United Nations Economic Commission for Europe",
+						Percent:          percents[domain.TaxSchemeIGV],
+						TaxScheme: &TaxScheme{
+							ID:             "1000",
+							SchemeID:       "UN/ECE 5153",
+							SchemeAgencyID: "6",
+							Name:           "IGV",
+							TaxTypeCode:    "VAT",
+						},
 					},
 				}},
+			}},
+		}
+		if line.ReferenceID != "" {
+			ublLine.BillingReference = &BillingReference{
+				InvoiceDocumentReference: &InvoiceDocumentReference{ID: line.ReferenceID, DocumentTypeCode: &DocumentTypeCode{Value: line.ReferenceDocType}},
+			}
+		}
+		ublSummary.SummaryDocumentsLines = append(ublSummary.SummaryDocumentsLines, ublLine)
+	}
+
+	return ublSummary, nil
+}
+
+// BuildReversalSummary maps a domain.ReversalSummary to a UBL VoidedDocuments structure.
+func BuildReversalSummary(summary *domain.ReversalSummary) (*VoidedDocuments, error) {
+	if len(summary.Lines) == 0 {
+		return nil, fmt.Errorf("el resumen %s no tiene comprobantes", summary.ID)
+	}
+
+	ublSummary := &VoidedDocuments{
+		Xmlns:    "urn:sunat:names:specification:ubl:peru:schema:xsd:VoidedDocuments-1",
+		XmlnsCAC: CAC,
+		XmlnsCBC: CBC,
+		XmlnsDS:  DS,
+		XmlnsEXT: EXT,
+		XmlnsSAC: SAC,
+		UBLExtensions: &UBLExtensions{
+			UBLExtension: &UBLExtension{
+				ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
 			},
-			Item: &Item{
-				Description: line.Description,
+		},
+		UBLVersionID:    "2.0",
+		CustomizationID: "1.0",
+		ID:              summary.ID,
+		ReferenceDate:   summary.ReferenceDate.Format("2006-01-02"),
+		IssueDate:       summary.IssueDate.Format("2006-01-02"),
+		Signature: &Signature{
+			ID: "IDSignSP",
+			SignatoryParty: &SignatoryParty{
+				PartyIdentification: &PartyIdentification{ID: summary.Issuer.RUC},
+				PartyName:           &PartyName{Name: summary.Issuer.Name},
 			},
-			Price: &Price{PriceAmount: &Amount{CurrencyID: dn.Currency, Value: line.UnitPrice}},
+			DigitalSignatureAttachment: &DigitalSignatureAttachment{
+				ExternalReference: &ExternalReference{URI: "#IDSignSP"},
+			},
+		},
+		AccountingSupplierParty: &Supplier{
+			CustomerAssignedAccountID: summary.Issuer.RUC,
+			AdditionalAccountID:       "6", // RUC
+			Party: &Party{
+				PartyLegalEntity: &PartyLegalEntity{RegistrationName: summary.Issuer.Name},
+			},
+		},
+	}
+
+	for i, line := range summary.Lines {
+		ublSummary.VoidedDocumentsLines = append(ublSummary.VoidedDocumentsLines, &VoidedDocumentsLine{
+			LineID:                strconv.Itoa(i + 1),
+			DocumentTypeCode:      line.DocType,
+			DocumentSerialID:      line.Series,
+			DocumentNumberID:      strconv.Itoa(line.Number),
+			VoidReasonDescription: line.Reason,
+		})
+	}
+
+	return ublSummary, nil
+}
+
+// BuildDespatchAdvice transforms a domain.DespatchAdvice into a UBL DespatchAdvice structure.
+func BuildDespatchAdvice(d *domain.DespatchAdvice) (*DespatchAdvice, error) {
+	if d.Type != domain.DespatchTypeSender && d.Type != domain.DespatchTypeCarrier {
+		return nil, fmt.Errorf("tipo de guía de remisión %q no soportado", d.Type)
+	}
+
+	ublDespatch := &DespatchAdvice{
+		Xmlns:           "urn:oasis:names:specification:ubl:schema:xsd:DespatchAdvice-2",
+		XmlnsCAC:        CAC,
+		XmlnsCBC:        CBC,
+		XmlnsDS:         DS,
+		XmlnsEXT:        EXT,
+		UBLVersionID:    "2.1",
+		CustomizationID: "2.0",
+		ID:              fmt.Sprintf("%s-%d", d.Series, d.Number),
+		IssueDate:       d.IssueDate.Format("2006-01-02"),
+		IssueTime:       d.IssueDate.Format("15:04:05"),
+		DespatchAdviceTypeCode: &DespatchAdviceTypeCode{
+			ListAgencyName: "PE:SUNAT",
+			ListName:       "Tipo de Documento",
+			ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo01",
+			Value:          d.Type,
+		},
+		Signature: &Signature{
+			ID: "IDSignSP",
+			SignatoryParty: &SignatoryParty{
+				PartyIdentification: &PartyIdentification{ID: d.Issuer.RUC},
+				PartyName:           &PartyName{Name: d.Issuer.Name},
+			},
+			DigitalSignatureAttachment: &DigitalSignatureAttachment{
+				ExternalReference: &ExternalReference{URI: "#IDSignSP"},
+			},
+		},
+		DespatchSupplierParty: &Supplier{
+			CustomerAssignedAccountID: d.Issuer.RUC,
+			AdditionalAccountID:       "6", // RUC
+			Party: &Party{
+				PartyLegalEntity: &PartyLegalEntity{RegistrationName: d.Issuer.Name},
+			},
+		},
+		DeliveryCustomerParty: &Customer{
+			CustomerAssignedAccountID: d.Recipient.DocNum,
+			AdditionalAccountID:       getDocType(d.Recipient.DocType),
+			Party: &Party{
+				PartyLegalEntity: &PartyLegalEntity{RegistrationName: d.Recipient.Name},
+			},
+		},
+	}
+	if d.Observation != "" {
+		ublDespatch.Notes = append(ublDespatch.Notes, &Note{Value: d.Observation})
+	}
+
+	// Shipment: remission guides report the departure point under Delivery/Despatch and the
+	// vehicle as transport equipment.
+	shipment := buildShipment(&d.Shipment)
+	shipment.ID = "SUNAT_Envio"
+	shipment.Delivery.Despatch = &Despatch{DespatchAddress: shipment.OriginAddress}
+	shipment.OriginAddress = nil
+	if means := shipment.ShipmentStage.TransportMeans; means != nil {
+		shipment.TransportHandlingUnit = &TransportHandlingUnit{TransportEquipment: &TransportEquipment{ID: means.RoadTransport.LicensePlateID}}
+		shipment.ShipmentStage.TransportMeans = nil
+	}
+	if d.Type == domain.DespatchTypeSender {
+		shipment.HandlingInstructions = d.Shipment.TransferReasonDescription()
+	} else {
+		// The carrier issues the guide: there is no transfer reason nor transport mode, and the
+		// sender of the goods is identified instead.
+		shipment.HandlingCode = nil
+		shipment.ShipmentStage.TransportModeCode = nil
+		shipment.ShipmentStage.CarrierParty = nil
+		if p := d.Shipper; p != nil {
+			shipment.Delivery.Despatch.DespatchParty = &DespatchParty{
+				PartyIdentification: &PartyIdentificationID{ID: &IdentityID{SchemeID: getDocType(p.DocType), Value: p.DocNum}},
+				PartyLegalEntity:    &PartyLegalEntity{RegistrationName: p.Name},
+			}
 		}
+	}
+	ublDespatch.Shipment = shipment
 
-		// Line Tax Totals (simplified for now, assuming only IGV)
-		lineTaxTotal := &TaxTotal{
-			TaxAmount: &Amount{CurrencyID: dn.Currency, Value: line.IGV},
-			TaxSubtotal: []*TaxSubtotal{{
-				TaxableAmount: &Amount{CurrencyID: dn.Currency, Value: line.TotalValue},
-				TaxAmount:     &Amount{CurrencyID: dn.Currency, Value: line.IGV},
-				TaxCategory: &TaxCategory{
-					ID:               "S",
-					SchemeID:         "UN/ECE 5305",
-					SchemeName:       "Tax Category Identifier",
-					SchemeAgencyName: "United Nations Economic Commission for Europe",
-					Percent:          18.00,
-					TaxExemptionReasonCode: &TaxExemptionReasonCode{
-						ListAgencyName: "PE:SUNAT",
-						ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
-						ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
-						Value:          "10", // Gravado - Operación Onerosa
-					},
-					TaxScheme: &TaxScheme{
-						ID:             "1000",
-						SchemeID:       "UN/ECE 5153",
-						SchemeAgencyID: "6",
-						Name:           "IGV",
-						TaxTypeCode:    "VAT",
-					},
-				},
+	// Despatch Lines
+	for i, line := range d.Lines {
+		lineID := strconv.Itoa(i + 1)
+		ublLine := &DespatchLine{
+			ID:                 lineID,
+			DeliveredQuantity:  &Quantity{UnitCode: line.Unit(), Value: line.Quantity},
+			OrderLineReference: &OrderLineReference{LineID: lineID},
+			Item:               &Item{Description: line.Description},
+		}
+		if line.Code != "" {
+			ublLine.Item.SellersItemIdentification = &SellersItemIdentification{ID: line.Code}
+		}
+		ublDespatch.DespatchLines = append(ublDespatch.DespatchLines, ublLine)
+	}
+
+	// Set UBLExtensions for signature
+	ublDespatch.UBLExtensions = &UBLExtensions{
+		UBLExtension: &UBLExtension{
+			ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
+		},
+	}
+
+	return ublDespatch, nil
+}
+
+// BuildRetention transforms a domain.Retention into a UBL Retention structure.
+func BuildRetention(r *domain.Retention) (*Retention, error) {
+	if len(r.Documents) == 0 {
+		return nil, fmt.Errorf("la retención no tiene comprobantes")
+	}
+
+	ublRetention := &Retention{
+		Xmlns:           "urn:sunat:names:specification:ubl:peru:schema:xsd:Retention-1",
+		XmlnsCAC:        CAC,
+		XmlnsCBC:        CBC,
+		XmlnsDS:         DS,
+		XmlnsEXT:        EXT,
+		XmlnsSAC:        SAC,
+		UBLVersionID:    "2.0",
+		CustomizationID: "1.0",
+		Signature: &Signature{
+			ID: "IDSignSP",
+			SignatoryParty: &SignatoryParty{
+				PartyIdentification: &PartyIdentification{ID: r.Issuer.RUC},
+				PartyName:           &PartyName{Name: r.Issuer.Name},
+			},
+			DigitalSignatureAttachment: &DigitalSignatureAttachment{
+				ExternalReference: &ExternalReference{URI: "#IDSignSP"},
+			},
+		},
+		ID:                 fmt.Sprintf("%s-%d", r.Series, r.Number),
+		IssueDate:          r.IssueDate.Format("2006-01-02"),
+		AgentParty:         buildWithholdingParty("6", r.Issuer.RUC, r.Issuer.Name),
+		ReceiverParty:      buildWithholdingParty(getDocType(r.Supplier.DocType), r.Supplier.DocNum, r.Supplier.Name),
+		SystemCode:         r.Regime,
+		Percent:            r.Percent,
+		TotalInvoiceAmount: &Amount{CurrencyID: "PEN", Value: r.TotalRetained},
+		TotalPaid:          &Amount{CurrencyID: "PEN", Value: r.TotalPaid},
+	}
+	if r.Observation != "" {
+		ublRetention.Notes = append(ublRetention.Notes, &Note{Value: r.Observation})
+	}
+
+	// Paid documents
+	for _, d := range r.Documents {
+		ref := buildWithholdingDocumentReference(d)
+		ref.RetentionInformation = &RetentionInformation{
+			RetentionAmount: &Amount{CurrencyID: "PEN", Value: d.Amount},
+			RetentionDate:   d.PaymentDate.Format("2006-01-02"),
+			NetTotalPaid:    &Amount{CurrencyID: "PEN", Value: d.NetAmount},
+			ExchangeRate:    buildExchangeRate(d),
+		}
+		ublRetention.RetentionDocumentReferences = append(ublRetention.RetentionDocumentReferences, ref)
+	}
+
+	// Set UBLExtensions for signature
+	ublRetention.UBLExtensions = &UBLExtensions{
+		UBLExtension: &UBLExtension{
+			ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
+		},
+	}
+
+	return ublRetention, nil
+}
+
+// BuildPerception transforms a domain.PerceptionReceipt into a UBL Perception structure.
+func BuildPerception(p *domain.PerceptionReceipt) (*Perception, error) {
+	if len(p.Documents) == 0 {
+		return nil, fmt.Errorf("la percepción no tiene comprobantes")
+	}
+
+	ublPerception := &Perception{
+		Xmlns:           "urn:sunat:names:specification:ubl:peru:schema:xsd:Perception-1",
+		XmlnsCAC:        CAC,
+		XmlnsCBC:        CBC,
+		XmlnsDS:         DS,
+		XmlnsEXT:        EXT,
+		XmlnsSAC:        SAC,
+		UBLVersionID:    "2.0",
+		CustomizationID: "1.0",
+		Signature: &Signature{
+			ID: "IDSignSP",
+			SignatoryParty: &SignatoryParty{
+				PartyIdentification: &PartyIdentification{ID: p.Issuer.RUC},
+				PartyName:           &PartyName{Name: p.Issuer.Name},
+			},
+			DigitalSignatureAttachment: &DigitalSignatureAttachment{
+				ExternalReference: &ExternalReference{URI: "#IDSignSP"},
+			},
+		},
+		ID:                 fmt.Sprintf("%s-%d", p.Series, p.Number),
+		IssueDate:          p.IssueDate.Format("2006-01-02"),
+		AgentParty:         buildWithholdingParty("6", p.Issuer.RUC, p.Issuer.Name),
+		ReceiverParty:      buildWithholdingParty(getDocType(p.Customer.DocType), p.Customer.DocNum, p.Customer.Name),
+		SystemCode:         p.Regime,
+		Percent:            p.Percent,
+		TotalInvoiceAmount: &Amount{CurrencyID: "PEN", Value: p.TotalPerceived},
+		TotalCashed:        &Amount{CurrencyID: "PEN", Value: p.TotalCashed},
+	}
+	if p.Observation != "" {
+		ublPerception.Notes = append(ublPerception.Notes, &Note{Value: p.Observation})
+	}
+
+	// Collected documents
+	for _, d := range p.Documents {
+		ref := buildWithholdingDocumentReference(d)
+		ref.PerceptionInformation = &PerceptionInformation{
+			PerceptionAmount: &Amount{CurrencyID: "PEN", Value: d.Amount},
+			PerceptionDate:   d.PaymentDate.Format("2006-01-02"),
+			NetTotalCashed:   &Amount{CurrencyID: "PEN", Value: d.NetAmount},
+			ExchangeRate:     buildExchangeRate(d),
+		}
+		ublPerception.PerceptionDocumentReferences = append(ublPerception.PerceptionDocumentReferences, ref)
+	}
+
+	// Set UBLExtensions for signature
+	ublPerception.UBLExtensions = &UBLExtensions{
+		UBLExtension: &UBLExtension{
+			ExtensionContent: &ExtensionContent{Placeholder: xml.Name{Local: "ds:Signature"}},
+		},
+	}
+
+	return ublPerception, nil
+}
+
+// buildBillingPayments returns the amount of a summarized document for each base that is not
+// zero, identified by its affectation type. Documents without amounts report a zero taxed base.
+func buildBillingPayments(t domain.Totals, currency string) []*BillingPayment {
+	bases := []struct {
+		instructionID string
+		amount        decimal.Decimal
+	}{
+		{"01", t.Taxable},    // Gravado
+		{"02", t.Exonerated}, // Exonerado
+		{"03", t.Unaffected}, // Inafecto
+		{"04", t.Exported},   // Exportación
+		{"05", t.Free},       // Gratuito
+	}
+	var result []*BillingPayment
+	for _, b := range bases {
+		if !b.amount.IsZero() {
+			result = append(result, &BillingPayment{PaidAmount: &Amount{CurrencyID: currency, Value: b.amount}, InstructionID: b.instructionID})
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, &BillingPayment{PaidAmount: &Amount{CurrencyID: currency, Value: t.Taxable}, InstructionID: "01"})
+	}
+	return result
+}
+
+// buildPrepayments returns the related document and the prepaid payment of each prepayment
+// deducted, numbered in order.
+func buildPrepayments(prepayments []domain.Prepayment, ruc, currency string) ([]*AdditionalDocumentReference, []*PrepaidPayment) {
+	var refs []*AdditionalDocumentReference
+	var payments []*PrepaidPayment
+	for i, p := range prepayments {
+		paymentID := strconv.Itoa(i + 1)
+		refs = append(refs, &AdditionalDocumentReference{
+			ID: p.ReferenceID,
+			DocumentTypeCode: &DocumentTypeCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Documento Relacionado",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo12",
+				Value:          p.DocType,
+			},
+			DocumentStatusCode: &DocumentStatusCode{ListName: "Anticipo", ListAgencyName: "PE:SUNAT", Value: paymentID},
+			IssuerParty:        &IssuerParty{PartyIdentification: &PartyIdentification{ID: ruc}},
+		})
+		payments = append(payments, &PrepaidPayment{
+			ID:         &PrepaidPaymentID{SchemeName: "Anticipo", SchemeAgencyName: "PE:SUNAT", Value: paymentID},
+			PaidAmount: &Amount{CurrencyID: currency, Value: p.Amount},
+		})
+	}
+	return refs, payments
+}
+
+// buildAllowanceCharges converts catalog 53 discounts and charges into their UBL form.
+func buildAllowanceCharges(items []domain.AllowanceCharge, currency string) []*AllowanceCharge {
+	var result []*AllowanceCharge
+	for _, ac := range items {
+		result = append(result, &AllowanceCharge{
+			ChargeIndicator: ac.IsCharge(),
+			AllowanceChargeReasonCode: &AllowanceChargeReasonCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Cargo/descuento",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo53",
+				Value:          ac.ReasonCode,
+			},
+			MultiplierFactorNumeric: optionalDecimal(ac.Factor),
+			Amount:                  &Amount{CurrencyID: currency, Value: ac.Amount},
+			BaseAmount:              &Amount{CurrencyID: currency, Value: ac.BaseAmount},
+		})
+	}
+	return result
+}
+
+// buildPaymentTerms maps the cash or credit payment terms to the FormaPago entries,
+// one for the method and pending amount and one per installment.
+func buildPaymentTerms(pt *domain.PaymentTerms, currency string) []*PaymentTerms {
+	if pt == nil {
+		return nil
+	}
+	terms := &PaymentTerms{ID: "FormaPago", PaymentMeansID: &PaymentMeansID{Value: pt.Method}}
+	if pt.Method == domain.PaymentCredit {
+		terms.Amount = &Amount{CurrencyID: currency, Value: pt.PendingAmount}
+	}
+	result := []*PaymentTerms{terms}
+	for i, c := range pt.Installments {
+		result = append(result, &PaymentTerms{
+			ID:             "FormaPago",
+			PaymentMeansID: &PaymentMeansID{Value: fmt.Sprintf("Cuota%03d", i+1)},
+			Amount:         &Amount{CurrencyID: currency, Value: c.Amount},
+			PaymentDueDate: c.DueDate.Format("2006-01-02"),
+		})
+	}
+	return result
+}
+
+// documentTypeCode returns a catalog 01 document type code.
+func documentTypeCode(code string) *DocumentTypeCode {
+	return &DocumentTypeCode{
+		ListAgencyName: "PE:SUNAT",
+		ListName:       "Tipo de Documento",
+		ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo01",
+		Value:          code,
+	}
+}
+
+// affectedDocType returns the type of the document referenced by a note, a factura by default.
+func affectedDocType(dr domain.DiscrepancyResponse) string {
+	if dr.DocType == "" {
+		return "01"
+	}
+	return dr.DocType
+}
+
+// operationType returns the catalog 51 operation type, internal sale by default.
+func operationType(code string) string {
+	if code == "" {
+		return domain.OperationInternalSale
+	}
+	return code
+}
+
+// taxPercents resolves the tax rates in force for the issuer regime on the issue date.
+func taxPercents(regime string, issueDate time.Time) (domain.TaxPercents, error) {
+	percents, err := domain.DefaultTaxRates.Percents(regime, issueDate)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la tasa de IGV: %w", err)
+	}
+	return percents, nil
+}
+
+// taxSchemeInfo describes how a catalog 05 tax scheme is reported in UBL.
+type taxSchemeInfo struct {
+	category    string // UN/ECE 5305 tax category
+	name        string
+	typeCode    string // UN/ECE 5153 tax type
+	affectation string // Catalog 07 code reported in the document subtotal
+}
+
+// taxSchemes holds the catalog 05 schemes a sale can be reported under.
+var taxSchemes = map[string]taxSchemeInfo{
+	domain.TaxSchemeIGV:        {category: "S", name: "IGV", typeCode: "VAT", affectation: "10"},
+	domain.TaxSchemeIVAP:       {category: "S", name: "IVAP", typeCode: "VAT", affectation: "17"},
+	domain.TaxSchemeExonerated: {category: "E", name: "EXO", typeCode: "VAT", affectation: "20"},
+	domain.TaxSchemeUnaffected: {category: "O", name: "INA", typeCode: "FRE", affectation: "30"},
+	domain.TaxSchemeExport:     {category: "G", name: "EXP", typeCode: "FRE", affectation: "40"},
+	domain.TaxSchemeFree:       {category: "Z", name: "GRA", typeCode: "FRE"},
+	domain.TaxSchemeIncomeTax:  {category: "S", name: "IR", typeCode: "OTH"},
+}
+
+// buildTaxTotal builds the document tax total with a subtotal for each tax scheme with
+// value. The IGV subtotal is reported when the document has no other.
+func buildTaxTotal(t domain.Totals, percents domain.TaxPercents, currency string) *TaxTotal {
+	total := &TaxTotal{TaxAmount: &Amount{CurrencyID: currency, Value: domain.Round2(t.IGV.Add(t.IVAP))}}
+	subtotals := []struct {
+		scheme    string
+		base, tax decimal.Decimal
+	}{
+		{domain.TaxSchemeIGV, t.Taxable, t.IGV},
+		{domain.TaxSchemeIVAP, t.IVAPBase, t.IVAP},
+		{domain.TaxSchemeExonerated, t.Exonerated, decimal.Zero},
+		{domain.TaxSchemeUnaffected, t.Unaffected, decimal.Zero},
+		{domain.TaxSchemeExport, t.Exported, decimal.Zero},
+		{domain.TaxSchemeFree, t.Free, t.FreeTax},
+	}
+	for _, s := range subtotals {
+		if s.base.IsZero() {
+			continue
+		}
+		total.TaxSubtotal = append(total.TaxSubtotal, buildTaxSubtotal(s.scheme, taxSchemes[s.scheme].affectation, s.base, s.tax, percents[s.scheme], currency))
+	}
+	if len(total.TaxSubtotal) == 0 {
+		total.TaxSubtotal = append(total.TaxSubtotal, buildTaxSubtotal(domain.TaxSchemeIGV, domain.AffectationTaxed, decimal.Zero, decimal.Zero, percents[domain.TaxSchemeIGV], currency))
+	}
+	return total
+}
+
+// buildLineTaxTotal builds the tax total of a line under the scheme of its catalog 07
+// affectation. Free transfers are reported under the free scheme (9996).
+func buildLineTaxTotal(line domain.InvoiceLine, percents domain.TaxPercents, currency string) *TaxTotal {
+	scheme := line.TaxScheme()
+	percent := percents[scheme]
+	if line.IsFree() {
+		scheme = domain.TaxSchemeFree
+	}
+	return &TaxTotal{
+		TaxAmount:   &Amount{CurrencyID: currency, Value: line.IGV},
+		TaxSubtotal: []*TaxSubtotal{buildTaxSubtotal(scheme, line.Affectation(), line.TotalValue, line.IGV, percent, currency)},
+	}
+}
+
+// buildTaxSubtotal builds the subtotal of a tax scheme, with its catalog 07 affectation when given.
+func buildTaxSubtotal(scheme, affectation string, base, tax, percent decimal.Decimal, currency string) *TaxSubtotal {
+	info := taxSchemes[scheme]
+	category := &TaxCategory{
+		ID:               info.category,
+		SchemeID:         "UN/ECE 5305",
+		SchemeName:       "Tax Category Identifier",
+		SchemeAgencyName: "United Nations Economic Commission for Europe",
+		Percent:          percent,
+		TaxScheme: &TaxScheme{
+			ID:             scheme,
+			SchemeID:       "UN/ECE 5153",
+			SchemeAgencyID: "6",
+			Name:           info.name,
+			TaxTypeCode:    info.typeCode,
+		},
+	}
+	if affectation != "" {
+		category.TaxExemptionReasonCode = &TaxExemptionReasonCode{
+			ListAgencyName: "PE:SUNAT",
+			ListName:       "SUNAT:Codigo de Tipo de Afectación del IGV",
+			ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo07",
+			Value:          affectation,
+		}
+	}
+	return &TaxSubtotal{
+		TaxableAmount: &Amount{CurrencyID: currency, Value: base},
+		TaxAmount:     &Amount{CurrencyID: currency, Value: tax},
+		TaxCategory:   category,
+	}
+}
+
+// buildPricingReference returns the catalog 16 reference price of a line: the unit price, or
+// the referential value of free transfers.
+func buildPricingReference(line domain.InvoiceLine, currency string) *PricingReference {
+	priceType := "01" // Precio unitario (incluye IGV)
+	if line.IsFree() {
+		priceType = "02" // Valor referencial unitario en operaciones no onerosas
+	}
+	return &PricingReference{
+		AlternativeConditionPrice: []*Price{{
+			PriceAmount: &Amount{CurrencyID: currency, Value: line.UnitPrice},
+			PriceTypeCode: &PriceTypeCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "SUNAT:Indicador de Tipo de Precio",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo16",
+				Value:          priceType,
+			},
+		}},
+	}
+}
+
+// buildAdditionalItemProperties returns the catalog 55 properties of a line, with the value in
+// the usability period when the property is a date, a time or a number of days.
+func buildAdditionalItemProperties(props []domain.ItemProperty) []*AdditionalItemProperty {
+	var properties []*AdditionalItemProperty
+	for _, p := range props {
+		property := &AdditionalItemProperty{
+			Name: p.Name(),
+			NameCode: &NameCode{
+				ListAgencyName: "PE:SUNAT",
+				ListName:       "Propiedad del item",
+				ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo55",
+				Value:          p.Code,
+			},
+		}
+		switch p.Kind() {
+		case domain.PropertyStartDate:
+			property.UsabilityPeriod = &UsabilityPeriod{StartDate: p.Value}
+		case domain.PropertyEndDate:
+			property.UsabilityPeriod = &UsabilityPeriod{EndDate: p.Value}
+		case domain.PropertyStartTime:
+			property.UsabilityPeriod = &UsabilityPeriod{StartTime: p.Value}
+		case domain.PropertyDuration:
+			days, _ := decimal.Parse(p.Value)
+			property.UsabilityPeriod = &UsabilityPeriod{DurationMeasure: &Quantity{UnitCode: "DAY", Value: days}}
+		default:
+			property.Value = p.Value
+		}
+		properties = append(properties, property)
+	}
+	return properties
+}
+
+// optionalDecimal returns nil for zero so that optional numeric elements are omitted.
+func optionalDecimal(d decimal.Decimal) *decimal.Decimal {
+	if d.IsZero() {
+		return nil
+	}
+	return &d
+}
+
+// buildNotes returns the catalog 52 legends of a document.
+func buildNotes(legends []domain.Legend) []*Note {
+	var notes []*Note
+	for _, l := range legends {
+		notes = append(notes, &Note{LanguageLocaleID: l.Code, Value: l.Value})
+	}
+	return notes
+}
+
+// buildPrice returns the unit value charged for a line, zero for free transfers.
+func buildPrice(line domain.InvoiceLine, currency string) *Price {
+	if line.IsFree() {
+		return &Price{PriceAmount: &Amount{CurrencyID: currency, Value: decimal.Zero}}
+	}
+	return &Price{PriceAmount: &Amount{CurrencyID: currency, Value: line.UnitPrice}}
+}
+
+// buildDelivery returns the delivery place and terms of an export.
+func buildDelivery(e *domain.ExportData) (*Delivery, *DeliveryTerms) {
+	if e == nil {
+		return nil, nil
+	}
+	var delivery *Delivery
+	if e.DestinationCountry != "" {
+		address := &Address{
+			Country: &Country{IdentificationCode: &CountryIdentificationCode{
+				ListID:         "ISO 3166-1",
+				ListAgencyName: "United Nations Economic Commission for Europe",
+				ListName:       "Country",
+				Value:          strings.ToUpper(e.DestinationCountry),
 			}},
 		}
-		ublDebitNote.TaxTotals = append(ublDebitNote.TaxTotals, lineTaxTotal)
+		if e.DestinationAddress != "" {
+			address.AddressLine = &AddressLine{Line: e.DestinationAddress}
+		}
+		delivery = &Delivery{DeliveryLocation: &DeliveryLocation{Address: address}}
+	}
+	var terms *DeliveryTerms
+	if e.Incoterm != "" {
+		terms = &DeliveryTerms{ID: strings.ToUpper(e.Incoterm)}
+	}
+	return delivery, terms
+}
 
-		ublDebitNote.DebitNoteLines = append(ublDebitNote.DebitNoteLines, ublLine)
+// addFreightDelivery adds to the delivery the route, the referential values (01 service, 02
+// effective load, 03 nominal load, always in PEN) and the loads in TM of a freight transport.
+// The loads join the shipment of a factura guía when there is one.
+func addFreightDelivery(delivery *Delivery, f *domain.FreightTransport) {
+	var loads []*MeasurementDimension
+	if f.EffectiveLoad.IsPositive() {
+		loads = append(loads, &MeasurementDimension{AttributeID: "01", Measure: &Quantity{UnitCode: "TNE", Value: f.EffectiveLoad}})
 	}
+	loads = append(loads, &MeasurementDimension{AttributeID: "02", Measure: &Quantity{UnitCode: "TNE", Value: f.NominalLoad}})
 
-	return ublDebitNote, nil
+	delivery.DeliveryLocation = &DeliveryLocation{Address: buildUbigeoAddress(f.Destination)}
+	delivery.Despatch = &Despatch{
+		Instructions:    f.TripDetail,
+		DespatchAddress: buildUbigeoAddress(f.Origin),
+	}
+	delivery.DeliveryTerms = []*DeliveryTerms{
+		{ID: "01", Amount: &Amount{CurrencyID: "PEN", Value: f.ReferentialValue}},
+		{ID: "02", Amount: &Amount{CurrencyID: "PEN", Value: f.EffectiveLoadValue}},
+		{ID: "03", Amount: &Amount{CurrencyID: "PEN", Value: f.NominalLoadValue}},
+	}
+	if delivery.Shipment == nil {
+		delivery.Shipment = &Shipment{ID: "01"}
+	}
+	if delivery.Shipment.TransportHandlingUnit == nil {
+		delivery.Shipment.TransportHandlingUnit = &TransportHandlingUnit{}
+	}
+	delivery.Shipment.TransportHandlingUnit.MeasurementDimensions = loads
 }
 
+// buildShipment maps the transport data of a factura guía to the UBL Shipment aggregate.
+func buildShipment(s *domain.Shipment) *Shipment {
+	stage := &ShipmentStage{
+		TransportModeCode: &TransportModeCode{
+			ListAgencyName: "PE:SUNAT",
+			ListName:       "SUNAT:Indicador de Modalidad de Transporte",
+			ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo18",
+			Value:          s.TransportMode,
+		},
+		TransitPeriod: &TransitPeriod{StartDate: s.StartDate.Format("2006-01-02")},
+	}
+	if c := s.Carrier; c != nil {
+		stage.CarrierParty = &CarrierParty{
+			PartyIdentification: &PartyIdentificationID{ID: &IdentityID{SchemeID: "6", Value: c.RUC}},
+			PartyLegalEntity:    &CarrierLegalEntity{RegistrationName: c.Name, CompanyID: c.MTCRegistration},
+		}
+	}
+	if s.VehiclePlate != "" {
+		stage.TransportMeans = &TransportMeans{RoadTransport: &RoadTransport{LicensePlateID: s.VehiclePlate}}
+	}
+	if d := s.Driver; d != nil {
+		stage.DriverPerson = &DriverPerson{
+			ID:                        &IdentityID{SchemeID: getDocType(d.DocType), Value: d.DocNum},
+			FirstName:                 d.FirstName,
+			FamilyName:                d.LastName,
+			JobTitle:                  "Principal",
+			IdentityDocumentReference: &IdentityDocumentReference{ID: d.License},
+		}
+	}
+	return &Shipment{
+		ID: "1",
+		HandlingCode: &HandlingCode{
+			ListAgencyName: "PE:SUNAT",
+			ListName:       "Motivo de traslado",
+			ListURI:        "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo20",
+			Value:          s.TransferReason,
+		},
+		GrossWeightMeasure: &Quantity{UnitCode: s.Weight(), Value: s.GrossWeight},
+		ShipmentStage:      stage,
+		Delivery:           &ShipmentDelivery{DeliveryAddress: buildUbigeoAddress(s.Destination)},
+		OriginAddress:      buildUbigeoAddress(s.Origin),
+	}
+}
+
+// buildUbigeoAddress returns an address identified by its INEI ubigeo.
+func buildUbigeoAddress(l domain.Location) *Address {
+	return &Address{
+		ID:          &AddressID{SchemeAgencyName: "PE:INEI", SchemeName: "Ubigeos", Value: l.Ubigeo},
+		AddressLine: &AddressLine{Line: l.Address},
+	}
+}
+
+// buildWithholdingParty identifies the agent or the counterpart of a retention or perception.
+func buildWithholdingParty(docType, docNum, name string) *WithholdingParty {
+	return &WithholdingParty{
+		PartyIdentification: &PartyIdentificationID{ID: &IdentityID{SchemeID: docType, Value: docNum}},
+		PartyName:           &PartyName{Name: name},
+		PartyLegalEntity:    &PartyLegalEntity{RegistrationName: name},
+	}
+}
+
+// buildWithholdingDocumentReference maps a paid or collected document and its payment.
+func buildWithholdingDocumentReference(d domain.WithholdingDocument) *WithholdingDocumentReference {
+	return &WithholdingDocumentReference{
+		ID:                 &DocumentReferenceID{SchemeID: d.DocType, Value: d.ReferenceID()},
+		IssueDate:          d.IssueDate.Format("2006-01-02"),
+		TotalInvoiceAmount: &Amount{CurrencyID: d.Currency, Value: d.Total},
+		Payment: &WithholdingPayment{
+			ID:         strconv.Itoa(d.PaymentNumber),
+			PaidAmount: &Amount{CurrencyID: d.Currency, Value: d.PaymentAmount},
+			PaidDate:   d.PaymentDate.Format("2006-01-02"),
+		},
+	}
+}
+
+// buildExchangeRate returns the exchange rate of a foreign currency payment, nil for PEN.
+func buildExchangeRate(d domain.WithholdingDocument) *ExchangeRate {
+	if d.Currency == "PEN" {
+		return nil
+	}
+	return &ExchangeRate{
+		SourceCurrencyCode: d.Currency,
+		TargetCurrencyCode: "PEN",
+		CalculationRate:    d.ExchangeRate,
+		Date:               d.PaymentDate.Format("2006-01-02"),
+	}
+}
+
+// getDocType returns the catalog 06 code of the recipient document type, "0" when it is
+// empty or unknown.
 func getDocType(docType string) string {
-	switch docType {
-	case "DNI":
-		return "1"
-	case "RUC":
-		return "6"
-	case "CE":
-		return "4"
-	default:
-		return "0"
-	}
-}
\ No newline at end of file
+	if code := domain.IdentityDocCode(docType); code != "" {
+		return code
+	}
+	return "0"
+}
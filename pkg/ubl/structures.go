@@ -1,6 +1,9 @@
 package ubl
 
-import "encoding/xml"
+import (
+	"FacturacionSunat/pkg/decimal"
+	"encoding/xml"
+)
 
 // Namespaces used in UBL XML
 const (
@@ -35,6 +38,7 @@ type Invoice struct {
 	IssueDate                   string                         `xml:"cbc:IssueDate"`
 	IssueTime                   string                         `xml:"cbc:IssueTime"`
 	InvoiceTypeCode             *InvoiceTypeCode               `xml:"cbc:InvoiceTypeCode"`
+	Notes                       []*Note                        `xml:"cbc:Note"`
 	DocumentCurrencyCode        *DocumentCurrencyCode          `xml:"cbc:DocumentCurrencyCode"`
 	LineCountNumeric            string                         `xml:"cbc:LineCountNumeric"`            // New for Invoice
 	OrderReference              *OrderReference                `xml:"cac:OrderReference"`              // New for Invoice
@@ -43,7 +47,14 @@ type Invoice struct {
 	Signature                   *Signature                     `xml:"cac:Signature"`
 	AccountingSupplierParty     *Supplier                      `xml:"cac:AccountingSupplierParty"`
 	AccountingCustomerParty     *Customer                      `xml:"cac:AccountingCustomerParty"`
+	Delivery                    *Delivery                      `xml:"cac:Delivery"`
+	DeliveryTerms               *DeliveryTerms                 `xml:"cac:DeliveryTerms"`
+	PaymentMeans                []*PaymentMeans                `xml:"cac:PaymentMeans"`
+	PaymentTerms                []*PaymentTerms                `xml:"cac:PaymentTerms"`
+	PrepaidPayments             []*PrepaidPayment              `xml:"cac:PrepaidPayment"`
+	AllowanceCharges            []*AllowanceCharge             `xml:"cac:AllowanceCharge"`
 	TaxTotals                   []*TaxTotal                    `xml:"cac:TaxTotal"`
+	WithholdingTaxTotals        []*TaxTotal                    `xml:"cac:WithholdingTaxTotal"` // Retentions of a purchase settlement
 	LegalMonetaryTotal          *MonetaryTotal                 `xml:"cac:LegalMonetaryTotal"`
 	InvoiceLines                []*InvoiceLine                 `xml:"cac:InvoiceLine"`
 }
@@ -60,7 +71,25 @@ type UBLExtension struct {
 
 // ExtensionContent holds the actual content of the extension, e.g., the digital signature.
 type ExtensionContent struct {
-	XMLName xml.Name `xml:",any"` // This will capture the ds:Signature element
+	Placeholder xml.Name `xml:"-"` // Name of the placeholder element, e.g. ds:Signature
+}
+
+// MarshalXML writes the extension content with an empty placeholder element that the
+// signer replaces with the real signature.
+func (c *ExtensionContent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if c.Placeholder.Local != "" {
+		placeholder := xml.StartElement{Name: c.Placeholder}
+		if err := e.EncodeToken(placeholder); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(placeholder.End()); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
 }
 
 // ProfileID defines the operation type.
@@ -90,24 +119,104 @@ type DocumentCurrencyCode struct {
 	Value          string   `xml:",chardata"`
 }
 
+// Note holds a legend of the document (catalog 52).
+type Note struct {
+	LanguageLocaleID string `xml:"languageLocaleID,attr,omitempty"`
+	Value            string `xml:",chardata"`
+}
+
 // OrderReference holds the purchase order number.
 type OrderReference struct {
 	XMLName xml.Name `xml:"cac:OrderReference"`
 	ID      string   `xml:"cbc:ID"`
 }
 
+// DocumentTypeCode defines the type of a referenced document.
+type DocumentTypeCode struct {
+	XMLName        xml.Name `xml:"cbc:DocumentTypeCode"`
+	ListAgencyName string   `xml:"listAgencyName,attr,omitempty"`
+	ListName       string   `xml:"listName,attr,omitempty"`
+	ListURI        string   `xml:"listURI,attr,omitempty"`
+	Value          string   `xml:",chardata"`
+}
+
 // DespatchDocumentReference holds reference to a despatch advice.
 type DespatchDocumentReference struct {
-	XMLName          xml.Name         `xml:"cac:DespatchDocumentReference"`
-	ID               string           `xml:"cbc:ID"`
-	DocumentTypeCode *InvoiceTypeCode `xml:"cbc:DocumentTypeCode"` // Reusing InvoiceTypeCode for simplicity
+	XMLName          xml.Name          `xml:"cac:DespatchDocumentReference"`
+	ID               string            `xml:"cbc:ID"`
+	DocumentTypeCode *DocumentTypeCode `xml:"cbc:DocumentTypeCode"`
 }
 
 // AdditionalDocumentReference holds reference to other related documents.
 type AdditionalDocumentReference struct {
-	XMLName          xml.Name         `xml:"cac:AdditionalDocumentReference"`
-	ID               string           `xml:"cbc:ID"`
-	DocumentTypeCode *InvoiceTypeCode `xml:"cbc:DocumentTypeCode"` // Reusing InvoiceTypeCode for simplicity
+	XMLName            xml.Name            `xml:"cac:AdditionalDocumentReference"`
+	ID                 string              `xml:"cbc:ID"`
+	DocumentTypeCode   *DocumentTypeCode   `xml:"cbc:DocumentTypeCode"`
+	DocumentStatusCode *DocumentStatusCode `xml:"cbc:DocumentStatusCode"`
+	IssuerParty        *IssuerParty        `xml:"cac:IssuerParty"`
+}
+
+// DocumentStatusCode links a related document with another element, e.g. a prepaid payment.
+type DocumentStatusCode struct {
+	XMLName        xml.Name `xml:"cbc:DocumentStatusCode"`
+	ListName       string   `xml:"listName,attr"`
+	ListAgencyName string   `xml:"listAgencyName,attr"`
+	Value          string   `xml:",chardata"`
+}
+
+// IssuerParty identifies the issuer of a related document.
+type IssuerParty struct {
+	PartyIdentification *PartyIdentification `xml:"cac:PartyIdentification"`
+}
+
+// PaymentMeans holds how the payment is made, e.g. the detraction deposit account.
+type PaymentMeans struct {
+	ID                    string                 `xml:"cbc:ID"`
+	PaymentMeansCode      *PaymentMeansCode      `xml:"cbc:PaymentMeansCode"`
+	PayeeFinancialAccount *PayeeFinancialAccount `xml:"cac:PayeeFinancialAccount"`
+}
+
+// PaymentMeansCode holds the catalog 59 payment method.
+type PaymentMeansCode struct {
+	ListName       string `xml:"listName,attr"`
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// PayeeFinancialAccount holds the account that receives the payment.
+type PayeeFinancialAccount struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// PaymentTerms holds payment conditions such as the detraction percentage and amount.
+type PaymentTerms struct {
+	ID             string           `xml:"cbc:ID"`
+	PaymentMeansID *PaymentMeansID  `xml:"cbc:PaymentMeansID"`
+	PaymentPercent *decimal.Decimal `xml:"cbc:PaymentPercent"`
+	Amount         *Amount          `xml:"cbc:Amount"`
+	PaymentDueDate string           `xml:"cbc:PaymentDueDate,omitempty"`
+}
+
+// PaymentMeansID identifies the payment condition, e.g. a catalog 54 detraction code.
+type PaymentMeansID struct {
+	SchemeName       string `xml:"schemeName,attr,omitempty"`
+	SchemeAgencyName string `xml:"schemeAgencyName,attr,omitempty"`
+	SchemeURI        string `xml:"schemeURI,attr,omitempty"`
+	Value            string `xml:",chardata"`
+}
+
+// PrepaidPayment holds a prepayment deducted from the invoice.
+type PrepaidPayment struct {
+	ID         *PrepaidPaymentID `xml:"cbc:ID"`
+	PaidAmount *Amount           `xml:"cbc:PaidAmount"`
+}
+
+// PrepaidPaymentID identifies a prepaid payment.
+type PrepaidPaymentID struct {
+	SchemeName       string `xml:"schemeName,attr"`
+	SchemeAgencyName string `xml:"schemeAgencyName,attr"`
+	Value            string `xml:",chardata"`
 }
 
 // Signature holds the digital signature information
@@ -192,6 +301,188 @@ type RegistrationAddress struct {
 	AddressTypeCode string `xml:"cbc:AddressTypeCode"`
 }
 
+// Delivery describes where the goods are delivered, e.g. the destination of an export, and
+// the shipment that carries them in a factura guía or a freight transport service.
+type Delivery struct {
+	DeliveryAddress  *Address          `xml:"cac:DeliveryAddress"`
+	DeliveryLocation *DeliveryLocation `xml:"cac:DeliveryLocation"`
+	Despatch         *Despatch         `xml:"cac:Despatch"`
+	DeliveryTerms    []*DeliveryTerms  `xml:"cac:DeliveryTerms"` // Referential values of a freight transport
+	Shipment         *Shipment         `xml:"cac:Shipment"`
+}
+
+// DeliveryLocation is the place of delivery.
+type DeliveryLocation struct {
+	Address *Address `xml:"cac:Address"`
+}
+
+// Address is a postal address.
+type Address struct {
+	ID          *AddressID   `xml:"cbc:ID"` // Ubigeo
+	AddressLine *AddressLine `xml:"cac:AddressLine"`
+	Country     *Country     `xml:"cac:Country"`
+}
+
+// AddressID is the INEI ubigeo of an address.
+type AddressID struct {
+	SchemeAgencyName string `xml:"schemeAgencyName,attr"`
+	SchemeName       string `xml:"schemeName,attr"`
+	Value            string `xml:",chardata"`
+}
+
+// AddressLine holds the free text of an address.
+type AddressLine struct {
+	Line string `xml:"cbc:Line"`
+}
+
+// Country identifies a country by its ISO 3166-1 code.
+type Country struct {
+	IdentificationCode *CountryIdentificationCode `xml:"cbc:IdentificationCode"`
+}
+
+// CountryIdentificationCode is an ISO 3166-1 alpha-2 country code.
+type CountryIdentificationCode struct {
+	ListID         string `xml:"listID,attr"`
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// DeliveryTerms holds the Incoterm of an export, or a referential value of a freight transport.
+type DeliveryTerms struct {
+	ID     string  `xml:"cbc:ID"`
+	Amount *Amount `xml:"cbc:Amount"`
+}
+
+// Shipment holds the transport data of the goods, as in a factura guía.
+type Shipment struct {
+	ID                    string                 `xml:"cbc:ID"`
+	HandlingCode          *HandlingCode          `xml:"cbc:HandlingCode"` // Catalog 20 transfer reason
+	GrossWeightMeasure    *Quantity              `xml:"cbc:GrossWeightMeasure"`
+	HandlingInstructions  string                 `xml:"cbc:HandlingInstructions,omitempty"` // Transfer reason description
+	ShipmentStage         *ShipmentStage         `xml:"cac:ShipmentStage"`
+	Delivery              *ShipmentDelivery      `xml:"cac:Delivery"`
+	TransportHandlingUnit *TransportHandlingUnit `xml:"cac:TransportHandlingUnit"`
+	OriginAddress         *Address               `xml:"cac:OriginAddress"`
+}
+
+// HandlingCode is the catalog 20 reason of a transfer.
+type HandlingCode struct {
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// ShipmentStage describes how the goods are transported.
+type ShipmentStage struct {
+	TransportModeCode *TransportModeCode `xml:"cbc:TransportModeCode"` // Catalog 18
+	TransitPeriod     *TransitPeriod     `xml:"cac:TransitPeriod"`
+	CarrierParty      *CarrierParty      `xml:"cac:CarrierParty"`
+	TransportMeans    *TransportMeans    `xml:"cac:TransportMeans"`
+	DriverPerson      *DriverPerson      `xml:"cac:DriverPerson"`
+}
+
+// TransportModeCode is the catalog 18 transport mode.
+type TransportModeCode struct {
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// TransitPeriod holds the start date of the transfer.
+type TransitPeriod struct {
+	StartDate string `xml:"cbc:StartDate"`
+}
+
+// CarrierParty is the transport company of a public transport.
+type CarrierParty struct {
+	PartyIdentification *PartyIdentificationID `xml:"cac:PartyIdentification"`
+	PartyLegalEntity    *CarrierLegalEntity    `xml:"cac:PartyLegalEntity"`
+}
+
+// PartyIdentificationID identifies a party with its catalog 06 document type.
+type PartyIdentificationID struct {
+	ID *IdentityID `xml:"cbc:ID"`
+}
+
+// IdentityID is a document number with its catalog 06 type.
+type IdentityID struct {
+	SchemeID         string `xml:"schemeID,attr"`
+	SchemeName       string `xml:"schemeName,attr,omitempty"`
+	SchemeAgencyName string `xml:"schemeAgencyName,attr,omitempty"`
+	SchemeURI        string `xml:"schemeURI,attr,omitempty"`
+	Value            string `xml:",chardata"`
+}
+
+// CarrierLegalEntity holds the name and MTC registration of a carrier.
+type CarrierLegalEntity struct {
+	RegistrationName string `xml:"cbc:RegistrationName"`
+	CompanyID        string `xml:"cbc:CompanyID,omitempty"` // MTC registration
+}
+
+// TransportMeans identifies the vehicle of a private transport.
+type TransportMeans struct {
+	RoadTransport *RoadTransport `xml:"cac:RoadTransport"`
+}
+
+// RoadTransport holds the plate of a vehicle.
+type RoadTransport struct {
+	LicensePlateID string `xml:"cbc:LicensePlateID"`
+}
+
+// DriverPerson is the driver of a private transport.
+type DriverPerson struct {
+	ID                        *IdentityID                `xml:"cbc:ID"`
+	FirstName                 string                     `xml:"cbc:FirstName,omitempty"`
+	FamilyName                string                     `xml:"cbc:FamilyName,omitempty"`
+	JobTitle                  string                     `xml:"cbc:JobTitle,omitempty"`
+	IdentityDocumentReference *IdentityDocumentReference `xml:"cac:IdentityDocumentReference"`
+}
+
+// IdentityDocumentReference holds the driving license of a driver.
+type IdentityDocumentReference struct {
+	ID string `xml:"cbc:ID"`
+}
+
+// ShipmentDelivery holds the arrival point of a shipment and, in remission guides, its
+// departure point and sender.
+type ShipmentDelivery struct {
+	DeliveryAddress *Address  `xml:"cac:DeliveryAddress"`
+	Despatch        *Despatch `xml:"cac:Despatch"`
+}
+
+// Despatch holds the departure point of a shipment and the sender of the goods.
+type Despatch struct {
+	Instructions    string         `xml:"cbc:Instructions,omitempty"` // Trip detail of a freight transport
+	DespatchAddress *Address       `xml:"cac:DespatchAddress"`
+	DespatchParty   *DespatchParty `xml:"cac:DespatchParty"`
+}
+
+// DespatchParty is the sender of the goods in a carrier remission guide.
+type DespatchParty struct {
+	PartyIdentification *PartyIdentificationID `xml:"cac:PartyIdentification"`
+	PartyLegalEntity    *PartyLegalEntity      `xml:"cac:PartyLegalEntity"`
+}
+
+// TransportHandlingUnit holds the vehicle that carries the goods of a remission guide.
+type TransportHandlingUnit struct {
+	TransportEquipment    *TransportEquipment     `xml:"cac:TransportEquipment"`
+	MeasurementDimensions []*MeasurementDimension `xml:"cac:MeasurementDimension"`
+}
+
+// MeasurementDimension is a load of a freight transport: 01 effective, 02 nominal.
+type MeasurementDimension struct {
+	AttributeID string    `xml:"cbc:AttributeID"`
+	Measure     *Quantity `xml:"cbc:Measure"`
+}
+
+// TransportEquipment identifies a vehicle by its plate.
+type TransportEquipment struct {
+	ID string `xml:"cbc:ID"`
+}
+
 // TaxTotal aggregates the total tax amounts
 type TaxTotal struct {
 	TaxAmount   *Amount        `xml:"cbc:TaxAmount"`
@@ -212,7 +503,7 @@ type TaxCategory struct {
 	SchemeID               string                  `xml:"schemeID,attr"`
 	SchemeName             string                  `xml:"schemeName,attr"`
 	SchemeAgencyName       string                  `xml:"schemeAgencyName,attr"`
-	Percent                float64                 `xml:"cbc:Percent"`
+	Percent                decimal.Decimal         `xml:"cbc:Percent"`
 	TaxExemptionReasonCode *TaxExemptionReasonCode `xml:"cbc:TaxExemptionReasonCode"`
 	TaxScheme              *TaxScheme              `xml:"cac:TaxScheme"`
 }
@@ -246,15 +537,34 @@ type MonetaryTotal struct {
 	PayableAmount        *Amount `xml:"cbc:PayableAmount"`
 }
 
+// AllowanceCharge is a discount or charge at document or line level
+type AllowanceCharge struct {
+	ChargeIndicator           bool                       `xml:"cbc:ChargeIndicator"`
+	AllowanceChargeReasonCode *AllowanceChargeReasonCode `xml:"cbc:AllowanceChargeReasonCode"`
+	MultiplierFactorNumeric   *decimal.Decimal           `xml:"cbc:MultiplierFactorNumeric"`
+	Amount                    *Amount                    `xml:"cbc:Amount"`
+	BaseAmount                *Amount                    `xml:"cbc:BaseAmount"`
+}
+
+// AllowanceChargeReasonCode holds the catalog 53 code of a discount or charge
+type AllowanceChargeReasonCode struct {
+	XMLName        xml.Name `xml:"cbc:AllowanceChargeReasonCode"`
+	ListAgencyName string   `xml:"listAgencyName,attr"`
+	ListName       string   `xml:"listName,attr"`
+	ListURI        string   `xml:"listURI,attr"`
+	Value          string   `xml:",chardata"`
+}
+
 // InvoiceLine represents one line item on the invoice
 type InvoiceLine struct {
-	ID                  string            `xml:"cbc:ID"` // Line number
-	InvoicedQuantity    *Quantity         `xml:"cbc:InvoicedQuantity"`
-	LineExtensionAmount *Amount           `xml:"cbc:LineExtensionAmount"` // Total sin IGV
-	PricingReference    *PricingReference `xml:"cac:PricingReference"`
-	TaxTotals           []*TaxTotal       `xml:"cac:TaxTotal"`
-	Item                *Item             `xml:"cac:Item"`
-	Price               *Price            `xml:"cac:Price"`
+	ID                  string             `xml:"cbc:ID"` // Line number
+	InvoicedQuantity    *Quantity          `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount *Amount            `xml:"cbc:LineExtensionAmount"` // Total sin IGV
+	PricingReference    *PricingReference  `xml:"cac:PricingReference"`
+	AllowanceCharges    []*AllowanceCharge `xml:"cac:AllowanceCharge"`
+	TaxTotals           []*TaxTotal        `xml:"cac:TaxTotal"`
+	Item                *Item              `xml:"cac:Item"`
+	Price               *Price             `xml:"cac:Price"`
 }
 
 // PricingReference holds pricing details
@@ -267,6 +577,31 @@ type Item struct {
 	Description               string                     `xml:"cbc:Description"`
 	SellersItemIdentification *SellersItemIdentification `xml:"cac:SellersItemIdentification"`
 	CommodityClassification   *CommodityClassification   `xml:"cac:CommodityClassification"`
+	AdditionalItemProperties  []*AdditionalItemProperty  `xml:"cac:AdditionalItemProperty"` // Catalog 55
+}
+
+// AdditionalItemProperty is a catalog 55 property of the item.
+type AdditionalItemProperty struct {
+	Name            string           `xml:"cbc:Name"`
+	NameCode        *NameCode        `xml:"cbc:NameCode"`
+	Value           string           `xml:"cbc:Value,omitempty"`
+	UsabilityPeriod *UsabilityPeriod `xml:"cac:UsabilityPeriod"`
+}
+
+// NameCode is the catalog 55 code of an item property.
+type NameCode struct {
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// UsabilityPeriod holds the dates, time or duration of an item property.
+type UsabilityPeriod struct {
+	StartDate       string    `xml:"cbc:StartDate,omitempty"`
+	StartTime       string    `xml:"cbc:StartTime,omitempty"`
+	EndDate         string    `xml:"cbc:EndDate,omitempty"`
+	DurationMeasure *Quantity `xml:"cbc:DurationMeasure"`
 }
 
 // SellersItemIdentification identifies the item by the seller
@@ -305,14 +640,21 @@ type PriceTypeCode struct {
 
 // Amount is a numeric value with a currency attribute
 type Amount struct {
-	CurrencyID string  `xml:"currencyID,attr"`
-	Value      float64 `xml:",chardata"`
+	CurrencyID string          `xml:"currencyID,attr"`
+	Value      decimal.Decimal `xml:",chardata"`
+}
+
+// MarshalXML writes the amount with at least the two decimals SUNAT expects, keeping the
+// additional decimals of unit values.
+func (a Amount) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "currencyID"}, Value: a.CurrencyID})
+	return e.EncodeElement(a.Value.Round(max(2, a.Value.Scale())), start)
 }
 
 // Quantity is a numeric value with a unit code attribute
 type Quantity struct {
-	UnitCode string  `xml:"unitCode,attr"`
-	Value    float64 `xml:",chardata"`
+	UnitCode string          `xml:"unitCode,attr"`
+	Value    decimal.Decimal `xml:",chardata"`
 }
 
 // BillingReference references a previous document
@@ -322,44 +664,57 @@ type BillingReference struct {
 
 // InvoiceDocumentReference holds the ID of the referenced invoice
 type InvoiceDocumentReference struct {
-	ID               string           `xml:"cbc:ID"`
-	DocumentTypeCode *InvoiceTypeCode `xml:"cbc:DocumentTypeCode"` // Reusing InvoiceTypeCode for simplicity
+	ID               string            `xml:"cbc:ID"`
+	DocumentTypeCode *DocumentTypeCode `xml:"cbc:DocumentTypeCode"`
 }
 
 // DiscrepancyResponse describes the reason for the note
 type DiscrepancyResponse struct {
-	ReferenceID  string `xml:"cbc:ReferenceID"`
-	ResponseCode string `xml:"cbc:ResponseCode"`
-	Description  string `xml:"cbc:Description"`
+	ReferenceID  string        `xml:"cbc:ReferenceID"`
+	ResponseCode *ResponseCode `xml:"cbc:ResponseCode"`
+	Description  string        `xml:"cbc:Description"`
+}
+
+// ResponseCode defines the note type (catalog 09 for credit notes, 10 for debit notes).
+type ResponseCode struct {
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
 }
 
 // CreditNote is the top-level UBL CreditNote structure
 type CreditNote struct {
-	XMLName                 xml.Name              `xml:"CreditNote"`
-	Xmlns                   string                `xml:"xmlns,attr"`
-	XmlnsCAC                string                `xml:"xmlns:cac,attr"`
-	XmlnsCBC                string                `xml:"xmlns:cbc,attr"`
-	XmlnsCCTS               string                `xml:"xmlns:ccts,attr"`
-	XmlnsDS                 string                `xml:"xmlns:ds,attr"`
-	XmlnsEXT                string                `xml:"xmlns:ext,attr"`
-	XmlnsQDT                string                `xml:"xmlns:qdt,attr"`
-	XmlnsUDT                string                `xml:"xmlns:udt,attr"`
-	XmlnsXSI                string                `xml:"xmlns:xsi,attr"`
-	UBLExtensions           *UBLExtensions        `xml:"ext:UBLExtensions"`
-	UBLVersionID            string                `xml:"cbc:UBLVersionID"`
-	CustomizationID         string                `xml:"cbc:CustomizationID"`
-	ID                      string                `xml:"cbc:ID"` // Serie-Numero
-	IssueDate               string                `xml:"cbc:IssueDate"`
-	IssueTime               string                `xml:"cbc:IssueTime"`
-	DocumentCurrencyCode    *DocumentCurrencyCode `xml:"cbc:DocumentCurrencyCode"`
-	DiscrepancyResponse     *DiscrepancyResponse  `xml:"cac:DiscrepancyResponse"`
-	BillingReference        *BillingReference     `xml:"cac:BillingReference"`
-	Signature               *Signature            `xml:"cac:Signature"`
-	AccountingSupplierParty *Supplier             `xml:"cac:AccountingSupplierParty"`
-	AccountingCustomerParty *Customer             `xml:"cac:AccountingCustomerParty"`
-	TaxTotals               []*TaxTotal           `xml:"cac:TaxTotal"`
-	LegalMonetaryTotal      *MonetaryTotal        `xml:"cac:LegalMonetaryTotal"`
-	CreditNoteLines         []*InvoiceLine        `xml:"cac:CreditNoteLine"` // Note: CreditNoteLine is same as InvoiceLine
+	XMLName                     xml.Name                       `xml:"CreditNote"`
+	Xmlns                       string                         `xml:"xmlns,attr"`
+	XmlnsCAC                    string                         `xml:"xmlns:cac,attr"`
+	XmlnsCBC                    string                         `xml:"xmlns:cbc,attr"`
+	XmlnsCCTS                   string                         `xml:"xmlns:ccts,attr"`
+	XmlnsDS                     string                         `xml:"xmlns:ds,attr"`
+	XmlnsEXT                    string                         `xml:"xmlns:ext,attr"`
+	XmlnsQDT                    string                         `xml:"xmlns:qdt,attr"`
+	XmlnsUDT                    string                         `xml:"xmlns:udt,attr"`
+	XmlnsXSI                    string                         `xml:"xmlns:xsi,attr"`
+	UBLExtensions               *UBLExtensions                 `xml:"ext:UBLExtensions"`
+	UBLVersionID                string                         `xml:"cbc:UBLVersionID"`
+	CustomizationID             string                         `xml:"cbc:CustomizationID"`
+	ID                          string                         `xml:"cbc:ID"` // Serie-Numero
+	IssueDate                   string                         `xml:"cbc:IssueDate"`
+	IssueTime                   string                         `xml:"cbc:IssueTime"`
+	Notes                       []*Note                        `xml:"cbc:Note"`
+	DocumentCurrencyCode        *DocumentCurrencyCode          `xml:"cbc:DocumentCurrencyCode"`
+	DiscrepancyResponse         *DiscrepancyResponse           `xml:"cac:DiscrepancyResponse"`
+	BillingReference            *BillingReference              `xml:"cac:BillingReference"`
+	AdditionalDocumentReference []*AdditionalDocumentReference `xml:"cac:AdditionalDocumentReference"` // Prepayments of an annulled invoice
+	Signature                   *Signature                     `xml:"cac:Signature"`
+	AccountingSupplierParty     *Supplier                      `xml:"cac:AccountingSupplierParty"`
+	AccountingCustomerParty     *Customer                      `xml:"cac:AccountingCustomerParty"`
+	PaymentTerms                []*PaymentTerms                `xml:"cac:PaymentTerms"`
+	PrepaidPayments             []*PrepaidPayment              `xml:"cac:PrepaidPayment"`
+	AllowanceCharges            []*AllowanceCharge             `xml:"cac:AllowanceCharge"`
+	TaxTotals                   []*TaxTotal                    `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal          *MonetaryTotal                 `xml:"cac:LegalMonetaryTotal"`
+	CreditNoteLines             []*InvoiceLine                 `xml:"cac:CreditNoteLine"` // Note: CreditNoteLine is same as InvoiceLine
 }
 
 // DebitNote is the top-level UBL DebitNote structure
@@ -380,6 +735,7 @@ type DebitNote struct {
 	ID                      string                `xml:"cbc:ID"` // Serie-Numero
 	IssueDate               string                `xml:"cbc:IssueDate"`
 	IssueTime               string                `xml:"cbc:IssueTime"`
+	Notes                   []*Note               `xml:"cbc:Note"`
 	DocumentCurrencyCode    *DocumentCurrencyCode `xml:"cbc:DocumentCurrencyCode"`
 	DiscrepancyResponse     *DiscrepancyResponse  `xml:"cac:DiscrepancyResponse"`
 	BillingReference        *BillingReference     `xml:"cac:BillingReference"`
@@ -390,3 +746,232 @@ type DebitNote struct {
 	LegalMonetaryTotal      *MonetaryTotal        `xml:"cac:LegalMonetaryTotal"`
 	DebitNoteLines          []*InvoiceLine        `xml:"cac:DebitNoteLine"` // Note: DebitNoteLine is same as InvoiceLine
 }
+
+// SummaryDocuments is the top-level UBL structure of the daily summary (resumen diario)
+// of boletas and the notes that reference them.
+type SummaryDocuments struct {
+	XMLName                 xml.Name                `xml:"SummaryDocuments"`
+	Xmlns                   string                  `xml:"xmlns,attr"`
+	XmlnsCAC                string                  `xml:"xmlns:cac,attr"`
+	XmlnsCBC                string                  `xml:"xmlns:cbc,attr"`
+	XmlnsDS                 string                  `xml:"xmlns:ds,attr"`
+	XmlnsEXT                string                  `xml:"xmlns:ext,attr"`
+	XmlnsSAC                string                  `xml:"xmlns:sac,attr"`
+	UBLExtensions           *UBLExtensions          `xml:"ext:UBLExtensions"`
+	UBLVersionID            string                  `xml:"cbc:UBLVersionID"`
+	CustomizationID         string                  `xml:"cbc:CustomizationID"`
+	ID                      string                  `xml:"cbc:ID"` // RC-YYYYMMDD-N
+	ReferenceDate           string                  `xml:"cbc:ReferenceDate"`
+	IssueDate               string                  `xml:"cbc:IssueDate"`
+	Signature               *Signature              `xml:"cac:Signature"`
+	AccountingSupplierParty *Supplier               `xml:"cac:AccountingSupplierParty"`
+	SummaryDocumentsLines   []*SummaryDocumentsLine `xml:"sac:SummaryDocumentsLine"`
+}
+
+// VoidedDocuments is the top-level UBL structure of the summaries that void documents. The
+// resumen de reversiones (RR) of retention and perception certificates uses it.
+type VoidedDocuments struct {
+	XMLName                 xml.Name               `xml:"VoidedDocuments"`
+	Xmlns                   string                 `xml:"xmlns,attr"`
+	XmlnsCAC                string                 `xml:"xmlns:cac,attr"`
+	XmlnsCBC                string                 `xml:"xmlns:cbc,attr"`
+	XmlnsDS                 string                 `xml:"xmlns:ds,attr"`
+	XmlnsEXT                string                 `xml:"xmlns:ext,attr"`
+	XmlnsSAC                string                 `xml:"xmlns:sac,attr"`
+	UBLExtensions           *UBLExtensions         `xml:"ext:UBLExtensions"`
+	UBLVersionID            string                 `xml:"cbc:UBLVersionID"`
+	CustomizationID         string                 `xml:"cbc:CustomizationID"`
+	ID                      string                 `xml:"cbc:ID"` // RR-YYYYMMDD-N
+	ReferenceDate           string                 `xml:"cbc:ReferenceDate"`
+	IssueDate               string                 `xml:"cbc:IssueDate"`
+	Signature               *Signature             `xml:"cac:Signature"`
+	AccountingSupplierParty *Supplier              `xml:"cac:AccountingSupplierParty"`
+	VoidedDocumentsLines    []*VoidedDocumentsLine `xml:"sac:VoidedDocumentsLine"`
+}
+
+// VoidedDocumentsLine identifies a single voided document and the reason.
+type VoidedDocumentsLine struct {
+	LineID                string `xml:"cbc:LineID"`
+	DocumentTypeCode      string `xml:"cbc:DocumentTypeCode"`
+	DocumentSerialID      string `xml:"sac:DocumentSerialID"`
+	DocumentNumberID      string `xml:"sac:DocumentNumberID"`
+	VoidReasonDescription string `xml:"sac:VoidReasonDescription"`
+}
+
+// SummaryDocumentsLine summarizes a single document of the daily summary.
+type SummaryDocumentsLine struct {
+	LineID                  string            `xml:"cbc:LineID"`
+	DocumentTypeCode        *DocumentTypeCode `xml:"cbc:DocumentTypeCode"`
+	ID                      string            `xml:"cbc:ID"` // Serie-Numero
+	AccountingCustomerParty *SummaryCustomer  `xml:"cac:AccountingCustomerParty"`
+	BillingReference        *BillingReference `xml:"cac:BillingReference"`
+	Status                  *SummaryStatus    `xml:"cac:Status"`
+	TotalAmount             *Amount           `xml:"sac:TotalAmount"`
+	BillingPayments         []*BillingPayment `xml:"sac:BillingPayment"`
+	TaxTotals               []*TaxTotal       `xml:"cac:TaxTotal"`
+}
+
+// SummaryCustomer identifies the customer of a summarized document.
+type SummaryCustomer struct {
+	CustomerAssignedAccountID string `xml:"cbc:CustomerAssignedAccountID"`
+	AdditionalAccountID       string `xml:"cbc:AdditionalAccountID"`
+}
+
+// SummaryStatus holds the condition of a summarized document: 1 add, 2 modify, 3 void.
+type SummaryStatus struct {
+	ConditionCode string `xml:"cbc:ConditionCode"`
+}
+
+// BillingPayment holds the amount of a summarized document by affectation type.
+type BillingPayment struct {
+	PaidAmount    *Amount `xml:"cbc:PaidAmount"`
+	InstructionID string  `xml:"cbc:InstructionID"` // 01 gravado, 02 exonerado, 03 inafecto, 04 exportación, 05 gratuito
+}
+
+// DespatchAdvice is the top-level UBL DespatchAdvice structure of remission guides (GRE).
+type DespatchAdvice struct {
+	XMLName                xml.Name                `xml:"DespatchAdvice"`
+	Xmlns                  string                  `xml:"xmlns,attr"`
+	XmlnsCAC               string                  `xml:"xmlns:cac,attr"`
+	XmlnsCBC               string                  `xml:"xmlns:cbc,attr"`
+	XmlnsDS                string                  `xml:"xmlns:ds,attr"`
+	XmlnsEXT               string                  `xml:"xmlns:ext,attr"`
+	UBLExtensions          *UBLExtensions          `xml:"ext:UBLExtensions"`
+	UBLVersionID           string                  `xml:"cbc:UBLVersionID"`
+	CustomizationID        string                  `xml:"cbc:CustomizationID"`
+	ID                     string                  `xml:"cbc:ID"` // Serie-Numero
+	IssueDate              string                  `xml:"cbc:IssueDate"`
+	IssueTime              string                  `xml:"cbc:IssueTime"`
+	DespatchAdviceTypeCode *DespatchAdviceTypeCode `xml:"cbc:DespatchAdviceTypeCode"`
+	Notes                  []*Note                 `xml:"cbc:Note"`
+	Signature              *Signature              `xml:"cac:Signature"`
+	DespatchSupplierParty  *Supplier               `xml:"cac:DespatchSupplierParty"`
+	DeliveryCustomerParty  *Customer               `xml:"cac:DeliveryCustomerParty"`
+	Shipment               *Shipment               `xml:"cac:Shipment"`
+	DespatchLines          []*DespatchLine         `xml:"cac:DespatchLine"`
+}
+
+// DespatchAdviceTypeCode is the catalog 01 type of a remission guide: 09 or 31.
+type DespatchAdviceTypeCode struct {
+	ListAgencyName string `xml:"listAgencyName,attr"`
+	ListName       string `xml:"listName,attr"`
+	ListURI        string `xml:"listURI,attr"`
+	Value          string `xml:",chardata"`
+}
+
+// DespatchLine is a good transported under a remission guide.
+type DespatchLine struct {
+	ID                 string              `xml:"cbc:ID"`
+	DeliveredQuantity  *Quantity           `xml:"cbc:DeliveredQuantity"`
+	OrderLineReference *OrderLineReference `xml:"cac:OrderLineReference"`
+	Item               *Item               `xml:"cac:Item"`
+}
+
+// OrderLineReference holds the line number of a despatch line.
+type OrderLineReference struct {
+	LineID string `xml:"cbc:LineID"`
+}
+
+// Retention is the top-level UBL structure of the comprobante de retención (Retention-1).
+type Retention struct {
+	XMLName                     xml.Name                        `xml:"Retention"`
+	Xmlns                       string                          `xml:"xmlns,attr"`
+	XmlnsCAC                    string                          `xml:"xmlns:cac,attr"`
+	XmlnsCBC                    string                          `xml:"xmlns:cbc,attr"`
+	XmlnsDS                     string                          `xml:"xmlns:ds,attr"`
+	XmlnsEXT                    string                          `xml:"xmlns:ext,attr"`
+	XmlnsSAC                    string                          `xml:"xmlns:sac,attr"`
+	UBLExtensions               *UBLExtensions                  `xml:"ext:UBLExtensions"`
+	UBLVersionID                string                          `xml:"cbc:UBLVersionID"`
+	CustomizationID             string                          `xml:"cbc:CustomizationID"`
+	Signature                   *Signature                      `xml:"cac:Signature"`
+	ID                          string                          `xml:"cbc:ID"` // Serie-Numero
+	IssueDate                   string                          `xml:"cbc:IssueDate"`
+	AgentParty                  *WithholdingParty               `xml:"cac:AgentParty"`
+	ReceiverParty               *WithholdingParty               `xml:"cac:ReceiverParty"`
+	SystemCode                  string                          `xml:"sac:SUNATRetentionSystemCode"` // Catalog 23
+	Percent                     decimal.Decimal                 `xml:"sac:SUNATRetentionPercent"`
+	Notes                       []*Note                         `xml:"cbc:Note"`
+	TotalInvoiceAmount          *Amount                         `xml:"cbc:TotalInvoiceAmount"` // Total retained
+	TotalPaid                   *Amount                         `xml:"sac:SUNATTotalPaid"`
+	RetentionDocumentReferences []*WithholdingDocumentReference `xml:"sac:SUNATRetentionDocumentReference"`
+}
+
+// Perception is the top-level UBL structure of the comprobante de percepción (Perception-1).
+type Perception struct {
+	XMLName                      xml.Name                        `xml:"Perception"`
+	Xmlns                        string                          `xml:"xmlns,attr"`
+	XmlnsCAC                     string                          `xml:"xmlns:cac,attr"`
+	XmlnsCBC                     string                          `xml:"xmlns:cbc,attr"`
+	XmlnsDS                      string                          `xml:"xmlns:ds,attr"`
+	XmlnsEXT                     string                          `xml:"xmlns:ext,attr"`
+	XmlnsSAC                     string                          `xml:"xmlns:sac,attr"`
+	UBLExtensions                *UBLExtensions                  `xml:"ext:UBLExtensions"`
+	UBLVersionID                 string                          `xml:"cbc:UBLVersionID"`
+	CustomizationID              string                          `xml:"cbc:CustomizationID"`
+	Signature                    *Signature                      `xml:"cac:Signature"`
+	ID                           string                          `xml:"cbc:ID"` // Serie-Numero
+	IssueDate                    string                          `xml:"cbc:IssueDate"`
+	AgentParty                   *WithholdingParty               `xml:"cac:AgentParty"`
+	ReceiverParty                *WithholdingParty               `xml:"cac:ReceiverParty"`
+	SystemCode                   string                          `xml:"sac:SUNATPerceptionSystemCode"` // Catalog 22
+	Percent                      decimal.Decimal                 `xml:"sac:SUNATPerceptionPercent"`
+	Notes                        []*Note                         `xml:"cbc:Note"`
+	TotalInvoiceAmount           *Amount                         `xml:"cbc:TotalInvoiceAmount"` // Total perceived
+	TotalCashed                  *Amount                         `xml:"sac:SUNATTotalCashed"`
+	PerceptionDocumentReferences []*WithholdingDocumentReference `xml:"sac:SUNATPerceptionDocumentReference"`
+}
+
+// WithholdingParty identifies the agent or the supplier/customer of a retention or perception.
+type WithholdingParty struct {
+	PartyIdentification *PartyIdentificationID `xml:"cac:PartyIdentification"`
+	PartyName           *PartyName             `xml:"cac:PartyName"`
+	PartyLegalEntity    *PartyLegalEntity      `xml:"cac:PartyLegalEntity"`
+}
+
+// WithholdingDocumentReference is a document paid or collected under a retention or perception.
+type WithholdingDocumentReference struct {
+	ID                    *DocumentReferenceID   `xml:"cbc:ID"`
+	IssueDate             string                 `xml:"cbc:IssueDate"`
+	TotalInvoiceAmount    *Amount                `xml:"cbc:TotalInvoiceAmount"`
+	Payment               *WithholdingPayment    `xml:"cac:Payment"`
+	RetentionInformation  *RetentionInformation  `xml:"sac:SUNATRetentionInformation"`
+	PerceptionInformation *PerceptionInformation `xml:"sac:SUNATPerceptionInformation"`
+}
+
+// DocumentReferenceID is the series and number of a document with its catalog 01 type.
+type DocumentReferenceID struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// WithholdingPayment is the payment of a document.
+type WithholdingPayment struct {
+	ID         string  `xml:"cbc:ID"`
+	PaidAmount *Amount `xml:"cbc:PaidAmount"`
+	PaidDate   string  `xml:"cbc:PaidDate"`
+}
+
+// RetentionInformation holds the amount retained from a payment.
+type RetentionInformation struct {
+	RetentionAmount *Amount       `xml:"sac:SUNATRetentionAmount"`
+	RetentionDate   string        `xml:"sac:SUNATRetentionDate"`
+	NetTotalPaid    *Amount       `xml:"sac:SUNATNetTotalPaid"`
+	ExchangeRate    *ExchangeRate `xml:"cac:ExchangeRate"`
+}
+
+// PerceptionInformation holds the amount perceived on a collection.
+type PerceptionInformation struct {
+	PerceptionAmount *Amount       `xml:"sac:SUNATPerceptionAmount"`
+	PerceptionDate   string        `xml:"sac:SUNATPerceptionDate"`
+	NetTotalCashed   *Amount       `xml:"sac:SUNATNetTotalCashed"`
+	ExchangeRate     *ExchangeRate `xml:"cac:ExchangeRate"`
+}
+
+// ExchangeRate is the rate used to express a foreign currency payment in PEN.
+type ExchangeRate struct {
+	SourceCurrencyCode string          `xml:"cbc:SourceCurrencyCode"`
+	TargetCurrencyCode string          `xml:"cbc:TargetCurrencyCode"`
+	CalculationRate    decimal.Decimal `xml:"cbc:CalculationRate"`
+	Date               string          `xml:"cbc:Date"`
+}